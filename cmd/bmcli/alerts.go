@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// runHistory bmcli history：查询告警记录
+func runHistory(opts *options, args []string) error {
+	fs := newFlagSet("history", "history [-node 节点] [-alert 类型] [-since 24h] [-until 时间] [-limit n]", opts)
+	node := fs.String("node", "", "节点标识或主机名")
+	alert := fs.String("alert", "", "告警类型，如 cpu、offline")
+	since := fs.String("since", "", "起始时间：RFC3339、Unix秒，或距今时长如 30m、24h、7d")
+	until := fs.String("until", "", "结束时间，格式同 -since")
+	limit := fs.Int("limit", 50, "最多显示的记录数")
+	if _, err := opts.parse(fs, args); err != nil {
+		return err
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(*limit)}}
+	if *node != "" {
+		query.Set("node", *node)
+	}
+	if *alert != "" {
+		query.Set("alert", *alert)
+	}
+	for name, value := range map[string]string{"since": *since, "until": *until} {
+		if value == "" {
+			continue
+		}
+		t, err := parseTimeArg(value)
+		if err != nil {
+			return usageError(fmt.Sprintf("-%s: %v", name, err))
+		}
+		query.Set(name, t)
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return err
+	}
+	var incidents []models.Incident
+	if _, err := client.call("GET", "/api/incidents", query, nil, &incidents); err != nil {
+		return err
+	}
+	if opts.json() {
+		return printJSON(incidents)
+	}
+	if len(incidents) == 0 {
+		fmt.Println("没有告警记录")
+		return nil
+	}
+	return printIncidents(incidents)
+}
+
+// parseTimeArg 将距今时长（30m、24h、7d）换算为Unix秒，其余原样交给服务端解析
+func parseTimeArg(value string) (string, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("无效的时长 %q", value)
+		}
+		return strconv.FormatInt(time.Now().AddDate(0, 0, -n).Unix(), 10), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return strconv.FormatInt(time.Now().Add(-d).Unix(), 10), nil
+	}
+	return value, nil
+}
+
+func printIncidents(incidents []models.Incident) error {
+	t := newTable("STARTED", "DURATION", "NODE", "ALERT", "PEAK", "THRESHOLD", "RESOLUTION")
+	for _, incident := range incidents {
+		resolution := incident.Resolution
+		if incident.EndedAt == nil {
+			resolution = "firing"
+		}
+		t.row(
+			incident.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			formatDuration(time.Duration(incident.Duration*float64(time.Second))),
+			incident.Hostname,
+			incident.Alert,
+			strconv.FormatFloat(incident.PeakValue, 'f', 2, 64),
+			strconv.FormatFloat(incident.Threshold, 'f', 2, 64),
+			resolution,
+		)
+	}
+	return t.flush()
+}
+
+// runSilence bmcli silence add|list|rm
+func runSilence(opts *options, args []string) error {
+	return subcommand("silence", args, map[string]func([]string) error{
+		"add": func(args []string) error {
+			fs := newFlagSet("silence add", "silence add [-hostname glob] [-alert 类型] -duration 2h [-comment 说明]", opts)
+			hostname := fs.String("hostname", "", "主机名glob，为空表示全部节点")
+			alert := fs.String("alert", "", "告警类型，为空表示全部类型")
+			duration := fs.Duration("duration", time.Hour, "静默时长，如 30m、2h")
+			comment := fs.String("comment", "", "说明")
+			if _, err := opts.parse(fs, args); err != nil {
+				return err
+			}
+			minutes := int(duration.Round(time.Minute).Minutes())
+			if minutes <= 0 {
+				return usageError("-duration 至少为1分钟")
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			req := models.SilenceRequest{
+				Hostname:        *hostname,
+				Alert:           *alert,
+				DurationMinutes: minutes,
+				Comment:         *comment,
+			}
+			var silence models.Silence
+			message, err := client.call("POST", "/api/silences", nil, req, &silence)
+			if err != nil {
+				return err
+			}
+			if opts.json() {
+				return printJSON(silence)
+			}
+			fmt.Printf("%s: %s（至 %s）\n", message, silence.ID, silence.EndsAt.Local().Format("2006-01-02 15:04:05"))
+			return nil
+		},
+		"list": func(args []string) error {
+			fs := newFlagSet("silence list", "silence list", opts)
+			if _, err := opts.parse(fs, args); err != nil {
+				return err
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var silences []models.Silence
+			if _, err := client.call("GET", "/api/silences", nil, nil, &silences); err != nil {
+				return err
+			}
+			if opts.json() {
+				return printJSON(silences)
+			}
+
+			t := newTable("ID", "HOSTNAME", "ALERT", "ENDS", "REMAINING", "COMMENT")
+			for _, silence := range silences {
+				t.row(
+					silence.ID,
+					orDash(silence.Hostname),
+					orDash(silence.Alert),
+					silence.EndsAt.Local().Format("2006-01-02 15:04:05"),
+					formatDuration(time.Until(silence.EndsAt)),
+					silence.Comment,
+				)
+			}
+			return t.flush()
+		},
+		"rm": func(args []string) error {
+			fs := newFlagSet("silence rm", "silence rm <ID>", opts)
+			args, err := opts.parse(fs, args)
+			if err != nil {
+				return err
+			}
+			if err := expectArgs(args, 1, "silence rm <ID>"); err != nil {
+				return err
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			message, err := client.call("DELETE", "/api/silences", url.Values{"id": {args[0]}}, nil, nil)
+			if err != nil {
+				return err
+			}
+			return printMessage(opts, message)
+		},
+	})
+}
+
+// runTestNotify bmcli test-notify：发送Telegram测试消息
+func runTestNotify(opts *options, args []string) error {
+	fs := newFlagSet("test-notify", "test-notify", opts)
+	if _, err := opts.parse(fs, args); err != nil {
+		return err
+	}
+	client, err := opts.client()
+	if err != nil {
+		return err
+	}
+	message, err := client.call("POST", "/api/test-telegram", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	return printMessage(opts, message)
+}
+
+// printMessage 输出没有数据的操作结果
+func printMessage(opts *options, message string) error {
+	if opts.json() {
+		return printJSON(map[string]interface{}{"success": true, "message": message})
+	}
+	fmt.Println(message)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/i18n"
+)
+
+// apiClient 服务端HTTP API客户端
+type apiClient struct {
+	server string
+	token  string
+	lang   string // Accept-Language，取自 LANG 环境变量，为空时使用服务端默认语言
+	http   *http.Client
+}
+
+// apiResponse 服务端通用响应，data 延迟到调用方解析
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// client 按 命令行参数 > 环境变量 > 保存的配置 确定服务端地址与凭证
+func (o *options) client() (*apiClient, error) {
+	server, token := o.server, o.token
+	if server == "" || token == "" {
+		file, err := loadProfiles()
+		if err != nil {
+			return nil, err
+		}
+		name := o.profile
+		if name == "" {
+			name = file.Current
+		}
+		if p, ok := file.Profiles[name]; ok {
+			if server == "" {
+				server = p.Server
+			}
+			if token == "" {
+				token = p.Token
+			}
+		} else if o.profile != "" {
+			return nil, fmt.Errorf("配置 %q 不存在，可用 bmcli profile list 查看", o.profile)
+		}
+	}
+	if server == "" {
+		return nil, usageError("未指定服务端地址，请使用 -server 参数、BM_SERVER 环境变量或 bmcli profile save 保存配置")
+	}
+
+	lang := ""
+	if l, ok := i18n.ParseLang(os.Getenv("LANG")); ok {
+		lang = string(l)
+	}
+
+	return &apiClient{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		lang:   lang,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// call 调用API并将 data 解析到 out（可为nil），返回服务端消息
+func (c *apiClient) call(method, path string, query url.Values, body, out interface{}) (string, error) {
+	endpoint := c.server + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.lang != "" {
+		req.Header.Set("Accept-Language", c.lang)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求服务端失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	var response apiResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("服务端返回 %s，响应无法解析: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if !response.Success {
+		return "", fmt.Errorf("%s（HTTP %d）", response.Message, resp.StatusCode)
+	}
+
+	if out != nil && len(response.Data) > 0 {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			return "", fmt.Errorf("响应解析失败: %v", err)
+		}
+	}
+	return response.Message, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// runConfig bmcli config validate：在本地检查配置文件，与 -check-config 规则相同
+func runConfig(opts *options, args []string) error {
+	return subcommand("config", args, map[string]func([]string) error{
+		"validate": func(args []string) error {
+			fs := newFlagSet("config validate", "config validate [-client] <配置文件>", opts)
+			client := fs.Bool("client", false, "按客户端配置检查，默认为服务端配置")
+			args, err := opts.parse(fs, args)
+			if err != nil {
+				return err
+			}
+			if err := expectArgs(args, 1, "config validate [-client] <配置文件>"); err != nil {
+				return err
+			}
+			path := args[0]
+
+			check := models.CheckServerConfigFile
+			if *client {
+				check = models.CheckClientConfigFile
+			}
+			var problems []string
+			if err := check(path); err != nil {
+				if errs, ok := err.(models.ConfigErrors); ok {
+					problems = errs
+				} else {
+					problems = []string{err.Error()}
+				}
+			}
+
+			if opts.json() {
+				if err := printJSON(struct {
+					File   string   `json:"file"`
+					Valid  bool     `json:"valid"`
+					Errors []string `json:"errors,omitempty"`
+				}{path, len(problems) == 0, problems}); err != nil {
+					return err
+				}
+			} else if len(problems) == 0 {
+				fmt.Printf("配置检查通过: %s\n", path)
+			} else {
+				for _, problem := range problems {
+					fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+				}
+			}
+
+			if len(problems) > 0 {
+				return fmt.Errorf("配置检查失败: %s（%d 个问题）", path, len(problems))
+			}
+			return nil
+		},
+	})
+}
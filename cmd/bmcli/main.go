@@ -0,0 +1,187 @@
+// bmcli 带宽监控服务端的命令行管理工具
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `用法: bmcli [全局参数] <命令> [参数]
+
+命令:
+  status                      列出节点状态与汇总
+  node <节点>                 查看单个节点详情及最近的告警记录
+  history                     查询告警记录
+  silence add|list|rm         管理告警静默规则
+  test-notify                 发送Telegram测试消息
+  nodes rm <节点>             删除节点
+  config validate <文件>      检查配置文件（本地执行，不连接服务端）
+  profile save|list|use|rm    管理保存的服务端地址与凭证
+
+全局参数（也可写在命令之后）:
+  -server URL     服务端地址，如 http://monitor.example.com:8080
+  -token TOKEN    访问凭证（viewer 或 admin 角色的API密钥）
+  -profile NAME   使用指定的已保存配置，默认为当前配置
+  -o table|json   输出格式，默认 table
+
+环境变量 BM_SERVER、BM_TOKEN、BM_PROFILE 可代替对应参数，BM_CONFIG 指定配置文件路径。
+使用 "bmcli <命令> -h" 查看命令参数。
+`
+
+// options 全局参数，优先级：命令行参数 > 环境变量 > 保存的配置
+type options struct {
+	server  string
+	token   string
+	profile string
+	output  string
+}
+
+// register 在参数集中注册全局参数，默认值为当前值，使全局参数可出现在命令前后
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.server, "server", o.server, "服务端地址")
+	fs.StringVar(&o.token, "token", o.token, "访问凭证")
+	fs.StringVar(&o.profile, "profile", o.profile, "使用指定的已保存配置")
+	fs.StringVar(&o.output, "o", o.output, "输出格式：table 或 json")
+}
+
+func (o *options) json() bool {
+	return o.output == "json"
+}
+
+// usageError 参数错误，以退出码2退出
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// command 子命令，args 不含命令名
+type command func(opts *options, args []string) error
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"status":      runStatus,
+		"node":        runNode,
+		"history":     runHistory,
+		"silence":     runSilence,
+		"test-notify": runTestNotify,
+		"nodes":       runNodes,
+		"config":      runConfig,
+		"profile":     runProfile,
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	opts := options{
+		server:  os.Getenv("BM_SERVER"),
+		token:   os.Getenv("BM_TOKEN"),
+		profile: os.Getenv("BM_PROFILE"),
+		output:  "table",
+	}
+
+	global := flag.NewFlagSet("bmcli", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	opts.register(global)
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Print(usage)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, usage)
+		return 2
+	}
+	args = global.Args()
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Print(usage)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n%s", args[0], usage)
+		return 2
+	}
+
+	err := cmd(&opts, args[1:])
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		// 参数解析错误已由 flag 包连同用法一起输出
+		if usageErr != "" {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		}
+		return 2
+	}
+	fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+	return 1
+}
+
+// newFlagSet 创建子命令参数集并注册全局参数
+func newFlagSet(name, synopsis string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: bmcli %s\n\n参数:\n", synopsis)
+		fs.PrintDefaults()
+	}
+	opts.register(fs)
+	return fs
+}
+
+// parse 解析子命令参数，允许参数与位置参数交错（如 bmcli node web-1 -o json），返回位置参数
+func (o *options) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError("")
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if o.output != "table" && o.output != "json" {
+		return nil, usageError(fmt.Sprintf("不支持的输出格式 %q，应为 table 或 json", o.output))
+	}
+	return positional, nil
+}
+
+// expectArgs 检查位置参数个数
+func expectArgs(args []string, n int, synopsis string) error {
+	if len(args) != n {
+		return usageError("用法: bmcli " + synopsis)
+	}
+	return nil
+}
+
+// subcommand 分派 silence add 这类二级命令
+func subcommand(name string, args []string, subs map[string]func([]string) error) error {
+	if len(args) == 0 {
+		return usageError(fmt.Sprintf("用法: bmcli %s %s", name, strings.Join(sortedKeys(subs), "|")))
+	}
+	sub, ok := subs[args[0]]
+	if !ok {
+		return usageError(fmt.Sprintf("未知命令: %s %s，可用: %s", name, args[0], strings.Join(sortedKeys(subs), "、")))
+	}
+	return sub(args[1:])
+}
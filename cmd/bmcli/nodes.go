@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// runStatus bmcli status：节点列表与汇总，筛选参数与 /api/status 相同
+func runStatus(opts *options, args []string) error {
+	fs := newFlagSet("status", "status [-online true|false] [-alerting] [-alert 类型] [-hostname glob] [-tag k:v] [-sort 字段] [-limit n]", opts)
+	online := fs.String("online", "", "按在线状态筛选：true 或 false")
+	alerting := fs.Bool("alerting", false, "只显示存在告警的节点")
+	alert := fs.String("alert", "", "只显示存在指定告警的节点，如 cpu、bandwidth")
+	hostname := fs.String("hostname", "", "主机名或显示名称，支持 glob")
+	var tags stringList
+	fs.Var(&tags, "tag", "按标签筛选，role:db 要求取值相等，role 只要求存在；可重复指定")
+	sortKey := fs.String("sort", "hostname", "排序字段，前缀 - 表示降序，如 -cpu_percent")
+	limit := fs.Int("limit", 0, "最多显示的节点数，0表示不限制")
+	if _, err := opts.parse(fs, args); err != nil {
+		return err
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"sort": {*sortKey}}
+	if *online != "" {
+		query.Set("online", *online)
+	}
+	if *alerting {
+		query.Set("alerting", "true")
+	}
+	if *alert != "" {
+		query.Set("alert", *alert)
+	}
+	if *hostname != "" {
+		query.Set("hostname", *hostname)
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+	for _, tag := range tags {
+		query.Add("tag", tag)
+	}
+
+	var list models.NodeList
+	if _, err := client.call("GET", "/api/status", query, nil, &list); err != nil {
+		return err
+	}
+	if opts.json() {
+		return printJSON(list)
+	}
+
+	var summary models.FleetSummary
+	if _, err := client.call("GET", "/api/summary", nil, nil, &summary); err != nil {
+		return err
+	}
+	fmt.Printf("节点 %d  在线 %d  离线 %d  停用 %d  告警 %d  入站 %.2f Mbps  出站 %.2f Mbps\n\n",
+		summary.Total, summary.Online, summary.Offline, summary.Disabled, summary.Alerting,
+		summary.NetworkInMbps, summary.NetworkOutMbps)
+
+	t := newTable("NODE", "NODE_ID", "STATUS", "CPU%", "MEM%", "IN_MBPS", "OUT_MBPS", "ALERTS", "LAST_SEEN")
+	for i := range list.Nodes {
+		node := &list.Nodes[i]
+		t.row(
+			node.Name(),
+			orDash(shortID(node.NodeID)),
+			nodeStatusText(node),
+			fmt.Sprintf("%.1f", node.Metrics.CPUPercent),
+			fmt.Sprintf("%.1f", node.Metrics.MemoryPercent()),
+			formatMbps(node.Metrics.NetworkInBps),
+			formatMbps(node.Metrics.NetworkOutBps),
+			orDash(strings.Join(node.ActiveAlerts(), ",")),
+			formatAgo(node.LastSeen),
+		)
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if list.Total > len(list.Nodes) {
+		fmt.Printf("\n共 %d 个节点，已显示 %d 个\n", list.Total, len(list.Nodes))
+	}
+	return nil
+}
+
+// runNode bmcli node <节点>：节点详情及最近的告警记录
+func runNode(opts *options, args []string) error {
+	fs := newFlagSet("node", "node [-history n] <节点标识|主机名|显示名称>", opts)
+	history := fs.Int("history", 5, "显示最近的告警记录条数，0表示不显示")
+	args, err := opts.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(args, 1, "node <节点标识|主机名|显示名称>"); err != nil {
+		return err
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return err
+	}
+	node, err := findNode(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	var incidents []models.Incident
+	if *history > 0 {
+		ref := node.NodeID
+		if ref == "" {
+			ref = node.Hostname
+		}
+		query := url.Values{"node": {ref}, "limit": {strconv.Itoa(*history)}}
+		if _, err := client.call("GET", "/api/incidents", query, nil, &incidents); err != nil {
+			return err
+		}
+	}
+
+	if opts.json() {
+		return printJSON(struct {
+			Node      models.NodeStatus `json:"node"`
+			Incidents []models.Incident `json:"incidents,omitempty"`
+		}{*node, incidents})
+	}
+
+	m := node.Metrics
+	fields := [][2]string{
+		{"主机名", node.Hostname},
+		{"显示名称", node.DisplayName},
+		{"节点标识", node.NodeID},
+		{"状态", nodeStatusText(node)},
+		{"标签", formatTags(node.Tags)},
+		{"最后上报", fmt.Sprintf("%s (%s)", node.LastSeen.Local().Format("2006-01-02 15:04:05"), formatAgo(node.LastSeen))},
+		{"告警", strings.Join(node.ActiveAlerts(), ", ")},
+		{"CPU", fmt.Sprintf("%.1f%%", m.CPUPercent)},
+		{"内存", fmt.Sprintf("%.1f%% (%.1f / %.1f GB)", m.MemoryPercent(), float64(m.MemoryUsed)/(1<<30), float64(m.MemoryTotal)/(1<<30))},
+		{"入站", formatMbps(m.NetworkInBps) + " Mbps"},
+		{"出站", formatMbps(m.NetworkOutBps) + " Mbps"},
+		{"带宽阈值", fmt.Sprintf("%.2f Mbps", node.LastThresholdMbps)},
+		{"运行时长", formatDuration(time.Duration(m.UptimeSeconds) * time.Second)},
+		{"时钟偏差", fmt.Sprintf("%+d 秒", node.ClockSkewSeconds)},
+		{"中继", node.Via},
+		{"配置版本", node.ConfigVersion},
+	}
+	if node.SpeedTest != nil {
+		speed := fmt.Sprintf("↓%.2f / ↑%.2f Mbps (%s)", node.SpeedTest.DownloadMbps, node.SpeedTest.UploadMbps,
+			formatAgo(time.Unix(node.SpeedTest.Timestamp, 0)))
+		if node.SpeedTest.Error != "" {
+			speed = node.SpeedTest.Error
+		}
+		fields = append(fields, [2]string{"测速", speed})
+	}
+	printFields(fields)
+
+	if len(incidents) > 0 {
+		fmt.Println()
+		return printIncidents(incidents)
+	}
+	return nil
+}
+
+// runNodes bmcli nodes rm <节点>
+func runNodes(opts *options, args []string) error {
+	return subcommand("nodes", args, map[string]func([]string) error{
+		"rm": func(args []string) error {
+			fs := newFlagSet("nodes rm", "nodes rm <节点标识|主机名>", opts)
+			args, err := opts.parse(fs, args)
+			if err != nil {
+				return err
+			}
+			if err := expectArgs(args, 1, "nodes rm <节点标识|主机名>"); err != nil {
+				return err
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var node models.NodeStatus
+			message, err := client.call("DELETE", "/api/nodes", url.Values{"node": {args[0]}}, nil, &node)
+			if err != nil {
+				return err
+			}
+			if opts.json() {
+				return printJSON(node)
+			}
+			fmt.Printf("%s: %s\n", message, node.Name())
+			return nil
+		},
+	})
+}
+
+// findNode 按节点标识（或表格中显示的标识前缀）、主机名或显示名称查找节点，匹配多个时要求使用完整节点标识
+func findNode(client *apiClient, ref string) (*models.NodeStatus, error) {
+	var list models.NodeList
+	if _, err := client.call("GET", "/api/status", url.Values{"sort": {"hostname"}}, nil, &list); err != nil {
+		return nil, err
+	}
+
+	var matched []*models.NodeStatus
+	for i := range list.Nodes {
+		node := &list.Nodes[i]
+		if node.NodeID == ref {
+			return node, nil
+		}
+		if node.Hostname == ref || node.DisplayName == ref ||
+			(len(ref) >= 8 && strings.HasPrefix(node.NodeID, ref)) {
+			matched = append(matched, node)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("节点 %s 不存在", ref)
+	case 1:
+		return matched[0], nil
+	}
+	ids := make([]string, len(matched))
+	for i, node := range matched {
+		ids[i] = node.NodeID
+	}
+	return nil, fmt.Errorf("%s 对应多个节点，请使用节点标识: %s", ref, strings.Join(ids, ", "))
+}
+
+// nodeStatusText 节点状态：停用/退役优先，其次在线/离线
+func nodeStatusText(node *models.NodeStatus) string {
+	if node.State != models.NodeStateActive {
+		return node.State
+	}
+	if node.IsOnline {
+		return "online"
+	}
+	return "offline"
+}
+
+// shortID 表格中只显示节点标识前8位
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// printJSON 以缩进JSON输出，便于脚本处理
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// table 按列对齐输出的表格
+type table struct {
+	w *tabwriter.Writer
+}
+
+func newTable(headers ...string) *table {
+	t := &table{w: tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)}
+	t.row(headers...)
+	return t
+}
+
+func (t *table) row(cells ...string) {
+	fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+func (t *table) flush() error {
+	return t.w.Flush()
+}
+
+// printFields 输出 名称: 值 形式的详情，跳过空值
+// 名称为中文，tabwriter按字节计算宽度无法对齐，因此不做对齐
+func printFields(fields [][2]string) {
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		fmt.Printf("%s: %s\n", field[0], field[1])
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatAgo 将时间格式化为距今时长，如 3m ago
+func formatAgo(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return formatDuration(time.Since(t)) + " ago"
+}
+
+// formatDuration 将时长格式化为 2d3h、3h5m、4m10s 这样的紧凑形式
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm%ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// formatMbps 将字节/秒速率格式化为 Mbps
+func formatMbps(bytesPerSecond uint64) string {
+	return fmt.Sprintf("%.2f", float64(bytesPerSecond)/125000.0)
+}
+
+// formatTags 将标签格式化为 k=v,k=v
+func formatTags(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		parts = append(parts, key+"="+tags[key])
+	}
+	return strings.Join(parts, ",")
+}
+
+// orDash 空字符串显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
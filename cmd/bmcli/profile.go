@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// profile 保存的服务端地址与凭证
+type profile struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+}
+
+// profileFile 保存的全部配置，文件包含凭证，权限为0600
+type profileFile struct {
+	Current  string             `json:"current"`
+	Profiles map[string]profile `json:"profiles"`
+}
+
+// profilePath 配置文件路径，默认为用户配置目录下的 bandwidth-monitor/bmcli.json
+func profilePath() (string, error) {
+	if path := os.Getenv("BM_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("无法确定配置目录: %v", err)
+	}
+	return filepath.Join(dir, "bandwidth-monitor", "bmcli.json"), nil
+}
+
+// loadProfiles 读取保存的配置，文件不存在时返回空配置
+func loadProfiles() (*profileFile, error) {
+	file := &profileFile{Profiles: make(map[string]profile)}
+
+	path, err := profilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败 %s: %v", path, err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = make(map[string]profile)
+	}
+	return file, nil
+}
+
+func (f *profileFile) save() error {
+	path, err := profilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// runProfile bmcli profile save|list|use|rm
+func runProfile(opts *options, args []string) error {
+	return subcommand("profile", args, map[string]func([]string) error{
+		"save": func(args []string) error {
+			fs := newFlagSet("profile save", "profile save [-name 名称] -server URL -token TOKEN", opts)
+			name := fs.String("name", "default", "配置名称")
+			if _, err := opts.parse(fs, args); err != nil {
+				return err
+			}
+			if opts.server == "" {
+				return usageError("请使用 -server 指定服务端地址")
+			}
+
+			file, err := loadProfiles()
+			if err != nil {
+				return err
+			}
+			file.Profiles[*name] = profile{Server: opts.server, Token: opts.token}
+			file.Current = *name
+			if err := file.save(); err != nil {
+				return err
+			}
+			fmt.Printf("已保存配置 %s 并设为当前配置\n", *name)
+
+			// 保存后检查一次连通性，失败只提示
+			client, err := opts.client()
+			if err == nil {
+				_, err = client.call("GET", "/api/summary", nil, nil, nil)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "警告: 无法访问服务端: %v\n", err)
+			}
+			return nil
+		},
+		"list": func(args []string) error {
+			fs := newFlagSet("profile list", "profile list", opts)
+			if _, err := opts.parse(fs, args); err != nil {
+				return err
+			}
+			file, err := loadProfiles()
+			if err != nil {
+				return err
+			}
+			if opts.json() {
+				// 不输出凭证
+				masked := profileFile{Current: file.Current, Profiles: make(map[string]profile)}
+				for name, p := range file.Profiles {
+					masked.Profiles[name] = profile{Server: p.Server, Token: maskToken(p.Token)}
+				}
+				return printJSON(masked)
+			}
+
+			t := newTable("CURRENT", "NAME", "SERVER", "TOKEN")
+			for _, name := range sortedKeys(file.Profiles) {
+				current := ""
+				if name == file.Current {
+					current = "*"
+				}
+				p := file.Profiles[name]
+				t.row(current, name, p.Server, maskToken(p.Token))
+			}
+			return t.flush()
+		},
+		"use": func(args []string) error {
+			fs := newFlagSet("profile use", "profile use <名称>", opts)
+			args, err := opts.parse(fs, args)
+			if err != nil {
+				return err
+			}
+			if err := expectArgs(args, 1, "profile use <名称>"); err != nil {
+				return err
+			}
+			file, err := loadProfiles()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Profiles[args[0]]; !ok {
+				return fmt.Errorf("配置 %q 不存在", args[0])
+			}
+			file.Current = args[0]
+			if err := file.save(); err != nil {
+				return err
+			}
+			fmt.Printf("当前配置: %s\n", args[0])
+			return nil
+		},
+		"rm": func(args []string) error {
+			fs := newFlagSet("profile rm", "profile rm <名称>", opts)
+			args, err := opts.parse(fs, args)
+			if err != nil {
+				return err
+			}
+			if err := expectArgs(args, 1, "profile rm <名称>"); err != nil {
+				return err
+			}
+			file, err := loadProfiles()
+			if err != nil {
+				return err
+			}
+			if _, ok := file.Profiles[args[0]]; !ok {
+				return fmt.Errorf("配置 %q 不存在", args[0])
+			}
+			delete(file.Profiles, args[0])
+			if file.Current == args[0] {
+				file.Current = ""
+			}
+			if err := file.save(); err != nil {
+				return err
+			}
+			fmt.Printf("已删除配置 %s\n", args[0])
+			return nil
+		},
+	})
+}
+
+// maskToken 只显示凭证首尾各两个字符
+func maskToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	if len(token) <= 6 {
+		return "******"
+	}
+	return token[:2] + "******" + token[len(token)-2:]
+}
@@ -4,37 +4,56 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"bandwidth-monitor/internal/client"
+	"bandwidth-monitor/internal/logging"
 	"bandwidth-monitor/internal/models"
 )
 
 func main() {
 	configPath := flag.String("config", "client.json", "配置文件路径")
+	checkConfig := flag.Bool("check-config", false, "检查配置文件后退出，有错误时返回非0")
 	flag.Parse()
 
+	if *checkConfig {
+		os.Exit(runConfigCheck(*configPath))
+	}
+
 	// 加载配置
 	config, err := loadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
+	// 日志配置无法应用时继续输出到标准错误
+	if err := logging.Setup(config.Log); err != nil {
+		slog.Warn("初始化日志失败，使用默认输出", "error", err)
+	}
+	// 已部署的客户端可能带有历史遗留的配置问题，启动时只提示不退出
+	if err := config.Validate(); err != nil {
+		slog.Warn("配置存在问题（可用 -check-config 查看）", "problems", err.Error())
+	}
 
 	// 创建客户端
 	c := client.NewClient(config, *configPath)
 
 	// 启动客户端
 	go func() {
-		log.Printf("客户端启动，连接到服务器: %s", config.ServerURL)
-		log.Printf("上报间隔: %d秒", config.ReportIntervalSeconds)
-		if config.InterfaceName != "" {
-			log.Printf("指定网卡: %s", config.InterfaceName)
+		if config.Transport == models.TransportGRPC {
+			slog.Info("客户端启动，通过gRPC连接到服务器", "grpc_address", config.GRPCAddress)
+		} else if config.ServerURL == "" {
+			slog.Info("客户端启动，仅抓取模式（等待服务器拉取）", "scrape_listen", config.ScrapeListen)
+		} else {
+			slog.Info("客户端启动，连接到服务器", "server_url", config.ServerURL)
 		}
+		slog.Info("上报配置", "interval_seconds", config.ReportIntervalSeconds, "interface", config.InterfaceName)
 
 		if err := c.Start(); err != nil {
-			log.Fatalf("客户端运行失败: %v", err)
+			slog.Error("客户端运行失败", "error", err)
+			os.Exit(1)
 		}
 	}()
 
@@ -43,9 +62,9 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	<-sigChan
 
-	log.Println("正在关闭客户端...")
+	slog.Info("正在关闭客户端...")
 	c.Stop()
-	log.Println("客户端已关闭")
+	slog.Info("客户端已关闭")
 }
 
 func loadConfig(path string) (*models.ClientConfig, error) {
@@ -71,6 +90,14 @@ func loadConfig(path string) (*models.ClientConfig, error) {
 					{Start: "09:00", End: "22:00", BandwidthMbps: 100}, // 平峰期
 				},
 			},
+			InterfaceFilter: models.InterfaceFilterConfig{
+				Exclude: models.DefaultInterfaceExcludes, // 默认排除回环与虚拟网卡
+			},
+			SpeedTest: models.SpeedTestConfig{
+				Enabled:         false,
+				IntervalMinutes: 0,
+				DurationSeconds: 5,
+			},
 		}
 
 		if err := saveConfig(path, defaultConfig); err != nil {
@@ -87,10 +114,29 @@ func loadConfig(path string) (*models.ClientConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	return config, nil
 }
 
 func saveConfig(path string, config *models.ClientConfig) error {
 	return models.SaveClientConfig(path, config)
 }
+
+// runConfigCheck 检查配置文件并逐条输出问题，返回进程退出码
+func runConfigCheck(path string) int {
+	err := models.CheckClientConfigFile(path)
+	if err == nil {
+		fmt.Printf("配置检查通过: %s\n", path)
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "配置检查失败: %s\n", path)
+	if errs, ok := err.(models.ConfigErrors); ok {
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "  - %s\n", e)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "  - %v\n", err)
+	}
+	return 1
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"bandwidth-monitor/internal/logging"
 	"bandwidth-monitor/internal/models"
 	"bandwidth-monitor/internal/server"
 	"bandwidth-monitor/internal/telegram"
@@ -15,43 +19,73 @@ import (
 
 func main() {
 	configPath := flag.String("config", "config.json", "配置文件路径")
+	checkConfig := flag.Bool("check-config", false, "检查配置文件后退出，有错误时返回非0")
 	flag.Parse()
 
+	if *checkConfig {
+		os.Exit(runConfigCheck(*configPath))
+	}
+
 	// 加载配置
 	config, err := loadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("配置无效: %v", err)
+	}
+	if err := logging.Setup(config.Log); err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
+	}
 
 	// 初始化Telegram机器人
 	var tgBot *telegram.Bot
 	if config.Telegram.BotToken != "" {
 		tgBot, err = telegram.NewBot(config.Telegram.BotToken, config.Telegram.ChatID)
 		if err != nil {
-			log.Fatalf("初始化Telegram机器人失败: %v", err)
+			slog.Error("初始化Telegram机器人失败", "error", err)
+			os.Exit(1)
 		}
-		log.Println("Telegram机器人初始化成功")
+		slog.Info("Telegram机器人初始化成功")
+	}
+
+	// 升级前 password 具备全部权限，未配置密钥时读取和管理接口均无法访问
+	if len(config.APIKeys) == 0 {
+		slog.Warn("未配置 api_keys，password 只能用于上报，/api/status、/api/silences、/api/test-telegram 等读取和管理接口将无法访问；请添加 viewer 或 admin 角色的密钥")
 	}
 
 	// 创建服务器
-	srv := server.NewServer(config, tgBot)
+	srv, err := server.NewServer(config, tgBot)
+	if err != nil {
+		slog.Error("创建服务器失败", "error", err)
+		os.Exit(1)
+	}
 
 	// 启动服务器
 	go func() {
-		log.Printf("服务器启动在 %s", config.Listen)
-		if err := srv.Start(); err != nil {
-			log.Fatalf("启动服务器失败: %v", err)
+		slog.Info("服务器启动", "listen", config.Listen)
+		if err := srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("启动服务器失败", "error", err)
+			os.Exit(1)
 		}
 	}()
 
+	// 配置文件变化或收到 SIGHUP 时热重载
+	go srv.WatchConfig(*configPath)
+
 	// 优雅关闭
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := <-c; sig == syscall.SIGHUP; sig = <-c {
+		slog.Info("收到 SIGHUP，重载配置")
+		if err := srv.Reload(*configPath); err != nil {
+			slog.Error("重载配置失败，继续使用原配置", "error", err)
+		}
+	}
 
-	log.Println("正在关闭服务器...")
+	slog.Info("正在关闭服务器...")
 	srv.Stop()
-	log.Println("服务器已关闭")
+	slog.Info("服务器已关闭")
 }
 
 func loadConfig(path string) (*models.ServerConfig, error) {
@@ -67,10 +101,12 @@ func loadConfig(path string) (*models.ServerConfig, error) {
 				ChatID:   0,
 			},
 			Thresholds: models.Threshold{
-				BandwidthMbps:  100.0, // 默认带宽阈值
-				OfflineSeconds: 300,   // 默认离线阈值
-				CPUPercent:     95.0,  // 默认CPU告警阈值
-				MemoryPercent:  95.0,  // 默认内存告警阈值
+				BandwidthMbps:    100.0, // 默认带宽阈值
+				OfflineSeconds:   300,   // 默认离线阈值
+				CPUPercent:       95.0,  // 默认CPU告警阈值
+				MemoryPercent:    95.0,  // 默认内存告警阈值
+				SpeedTestMbps:    0,     // 默认不启用测速告警
+				ClockSkewSeconds: 30,    // 默认时钟偏差告警阈值
 			},
 		}
 
@@ -88,10 +124,29 @@ func loadConfig(path string) (*models.ServerConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	return config, nil
 }
 
 func saveConfig(path string, config *models.ServerConfig) error {
 	return models.SaveServerConfig(path, config)
 }
+
+// runConfigCheck 检查配置文件并逐条输出问题，返回进程退出码
+func runConfigCheck(path string) int {
+	err := models.CheckServerConfigFile(path)
+	if err == nil {
+		fmt.Printf("配置检查通过: %s\n", path)
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "配置检查失败: %s\n", path)
+	if errs, ok := err.(models.ConfigErrors); ok {
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "  - %s\n", e)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "  - %v\n", err)
+	}
+	return 1
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bandwidth-monitor/internal/compress"
+	"bandwidth-monitor/internal/models"
+)
+
+// 批量模式下最多缓存的批次数，超出时丢弃最旧的上报
+const maxPendingBatches = 10
+
+func (c *Client) getBatchConfig() (int, time.Duration, string) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.BatchSize, time.Duration(c.config.BatchMaxDelaySeconds) * time.Second, c.config.Compression
+}
+
+// postJSON 发送（可压缩的）JSON请求并解析通用响应
+func (c *Client) postJSON(url string, request interface{}, compression string, data interface{}) error {
+	body, encoding, err := compress.EncodeJSON(request, compression)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	response := models.APIResponse{Data: data}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("响应解析失败: %v", err)
+	}
+
+	if !response.Success {
+		return fmt.Errorf("服务器返回错误: %s", response.Message)
+	}
+
+	return nil
+}
+
+// queueBatchReport 将上报加入队列，达到批量大小或最早一条等待超过 maxDelay 时一次发送，
+// 避免数据到达间隔超过服务端离线判断时间；发送失败的上报保留在队列中等待下次重发
+func (c *Client) queueBatchReport(request models.ReportRequest, serverURL, password string, batchSize int, maxDelay time.Duration, compression string) error {
+	if len(c.pendingReports) == 0 {
+		c.pendingSince = time.Now()
+	}
+	c.pendingReports = append(c.pendingReports, request)
+	if limit := batchSize * maxPendingBatches; len(c.pendingReports) > limit {
+		dropped := len(c.pendingReports) - limit
+		c.pendingReports = c.pendingReports[dropped:]
+		reportLog.Warn("待发送上报过多，丢弃最旧的上报", "dropped", dropped)
+	}
+
+	if len(c.pendingReports) < batchSize && (maxDelay <= 0 || time.Since(c.pendingSince) < maxDelay) {
+		return nil
+	}
+
+	batch := models.BatchReportRequest{
+		Password: password,
+		Reports:  c.pendingReports,
+	}
+
+	var batchResp models.BatchReportResponse
+	url := fmt.Sprintf("%s/api/report/batch", serverURL)
+	if err := c.postJSON(url, batch, compression, &batchResp); err != nil {
+		return fmt.Errorf("批量上报失败（%d 条待重发）: %v", len(c.pendingReports), err)
+	}
+
+	c.pendingReports = nil
+	reportLog.Info("批量上报完成", "reports", len(batch.Reports), "accepted", batchResp.Accepted, "duplicates", batchResp.Duplicates)
+
+	if resp, ok := batchResp.Responses[request.NodeKey()]; ok {
+		c.handleReportResponse(request, resp)
+	}
+
+	return nil
+}
@@ -1,53 +1,138 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 	_ "time/tzdata" // 内嵌时区数据
 
+	"bandwidth-monitor/internal/logging"
 	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/rpc"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/net"
+	"google.golang.org/grpc"
+)
+
+// 各模块的日志记录器
+var (
+	collectorLog = logging.Component("collector") // 指标采集、网卡选择
+	reportLog    = logging.Component("reporter")  // 上报、心跳、中继、测速
+	configLog    = logging.Component("config")    // 配置重载、时区与时钟
 )
 
 type Client struct {
 	config        *models.ClientConfig
 	configPath    string
+	nodeID        string // 稳定的节点标识，Start 时确定
 	configMutex   sync.RWMutex
 	httpClient    *http.Client
 	stopChan      chan struct{}
 	wg            sync.WaitGroup
-	lastNetStats  map[string]net.IOCountersStat
-	lastSampleAt  time.Time
+	netTracker    *netTracker // 上报间隔的网卡计数跟踪
 	configModTime time.Time
 	currentTZ     *time.Location // 当前时区
-	tzMutex       sync.RWMutex   // 时区读写锁
+	tzMutex       sync.RWMutex   // 时区与时钟校正读写锁
+	clockSkew     time.Duration  // 最近一次测得的服务端时间减本地时间
+	clockOffset   time.Duration  // 应用到本地时间的校正量（未启用校正时为0）
+
+	// 测速状态
+	speedTestMutex   sync.Mutex
+	speedTestRunning bool
+	speedTestResult  *models.SpeedTestResult // 待随下次上报发送的测速结果
+	speedTestAcked   int64                   // 抓取模式下服务端已确认记录的测速结果时间戳
+	lastSpeedTestAt  time.Time
+
+	// 子采样状态
+	samplerMutex  sync.Mutex
+	samples       []subSample
+	sampleTracker *netTracker // 子采样的网卡计数跟踪
+
+	// 进程快照状态（仅在上报循环中访问）
+	procTracker           *procTracker
+	topProcessesRequested bool
+
+	// gRPC上报连接（仅在上报循环中访问）
+	grpcConn    *grpc.ClientConn
+	grpcStream  rpc.Monitor_ReportClient
+	grpcCancel  context.CancelFunc
+	grpcAddress string
+	grpcTLS     models.GRPCTLSConfig
+
+	// 校验失败的下发配置版本，服务端重复下发同一版本时不再重试（仅在上报循环中访问）
+	rejectedConfigVersion string
+
+	// 批量模式下待发送的上报及其中最早一条的入队时间（仅在上报循环中访问）
+	pendingReports []models.ReportRequest
+	pendingSince   time.Time
+
+	// 本进程的上报标识与序号，服务端据此识别重发的上报（仅在上报循环中访问）
+	session   string
+	reportSeq int64
+
+	// 抓取接口
+	scrapeServer   *http.Server
+	latestReport   *models.ReportRequest
+	scrapeResponse *models.ReportResponse // 服务端回传、待上报循环处理的响应
+	latestMutex    sync.RWMutex
+
+	// 中继状态
+	relayServer    *http.Server
+	relayMutex     sync.Mutex
+	relayBuffer    []models.ReportRequest
+	relayResponses map[string]*models.ReportResponse // 待转交给本地节点的服务端响应（按 NodeKey）
+	relayDropped   int
 }
 
 func NewClient(config *models.ClientConfig, configPath string) *Client {
 	return &Client{
-		config:       config,
-		configPath:   configPath,
+		config:     config,
+		configPath: configPath,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		stopChan:     make(chan struct{}),
-		lastNetStats: make(map[string]net.IOCountersStat),
-		currentTZ:    time.Local, // 初始化为本地时区
+		stopChan:      make(chan struct{}),
+		netTracker:    newNetTracker("上报"),
+		sampleTracker: newNetTracker("子采样"),
+		procTracker:   newProcTracker(),
+		currentTZ:     time.Local, // 初始化为本地时区
+		session:       newSession(),
 	}
 }
 
+// newSession 生成本进程的上报标识，随机数不可用时退回启动时间
+func newSession() string {
+	id, err := newUUID()
+	if err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return id
+}
+
+// nextReportSeq 返回下一个上报序号，不受本地时钟调整影响
+func (c *Client) nextReportSeq() int64 {
+	c.reportSeq++
+	return c.reportSeq
+}
+
 func (c *Client) Start() error {
+	// 确定节点标识，失败时仅按主机名区分节点
+	nodeID, err := loadNodeID(c.configPath)
+	if err != nil {
+		configLog.Warn("获取节点标识失败，仅按主机名上报", "error", err)
+	} else {
+		configLog.Info("节点标识", "node_id", nodeID)
+	}
+	c.nodeID = nodeID
+
 	// 初始化配置文件修改时间
 	c.updateConfigModTime()
 
@@ -55,9 +140,26 @@ func (c *Client) Start() error {
 	c.wg.Add(1)
 	go c.configWatcher()
 
+	// 启动子采样 goroutine
+	c.wg.Add(1)
+	go c.sampler()
+
+	// 启动UDP心跳 goroutine
+	c.wg.Add(1)
+	go c.heartbeatLoop()
+
+	// 启动抓取接口（可选）
+	if err := c.startScrapeServer(); err != nil {
+		return err
+	}
+
+	// 启动中继接口（可选）
+	if err := c.startRelayServer(); err != nil {
+		return err
+	}
+
 	// 选择监控网卡
-	interfaceInfo := c.getInterfaceInfo()
-	log.Printf("网卡配置: %s", interfaceInfo)
+	collectorLog.Info("网卡配置", "interfaces", c.getInterfaceInfo())
 
 	currentInterval := c.getReportInterval()
 	ticker := time.NewTicker(time.Duration(currentInterval) * time.Second)
@@ -65,7 +167,7 @@ func (c *Client) Start() error {
 
 	// 立即发送一次报告
 	if err := c.reportMetrics(); err != nil {
-		log.Printf("首次上报失败: %v", err)
+		reportLog.Error("首次上报失败", "error", err)
 	}
 
 	for {
@@ -74,15 +176,17 @@ func (c *Client) Start() error {
 			// 检查配置是否更新了上报间隔
 			newInterval := c.getReportInterval()
 			if newInterval != currentInterval {
-				log.Printf("上报间隔已更新: %d秒 -> %d秒", currentInterval, newInterval)
+				configLog.Info("上报间隔已更新", "old_seconds", currentInterval, "new_seconds", newInterval)
 				currentInterval = newInterval
 				ticker.Stop()
 				ticker = time.NewTicker(time.Duration(newInterval) * time.Second)
 			}
 
 			if err := c.reportMetrics(); err != nil {
-				log.Printf("上报失败: %v", err)
+				reportLog.Error("上报失败", "error", err)
 			}
+
+			c.checkSpeedTestSchedule()
 		case <-c.stopChan:
 			return nil
 		}
@@ -93,31 +197,31 @@ func (c *Client) Start() error {
 func (c *Client) reloadSystemTimezone() error {
 	c.tzMutex.Lock()
 	defer c.tzMutex.Unlock()
-	
+
 	// 首先获取系统当前实际使用的时区
 	systemTime := time.Now()
 	currentSystemTZ := systemTime.Location()
-	
+
 	// 检查是否需要更新时区
 	if c.currentTZ.String() == currentSystemTZ.String() {
 		// 时区没有变化，无需更新
 		return nil
 	}
-	
+
 	// 验证新时区是否有效
 	testTime := time.Now().In(currentSystemTZ)
 	if testTime.IsZero() {
-		log.Printf("无效的系统时区，保持当前时区: %s", c.currentTZ.String())
+		configLog.Warn("无效的系统时区，保持当前时区", "timezone", c.currentTZ.String())
 		return fmt.Errorf("无效的系统时区")
 	}
-	
+
 	// 更新时区
 	oldTZ := c.currentTZ
 	c.currentTZ = currentSystemTZ
-	
-	log.Printf("系统时区已热重载: %s -> %s", oldTZ.String(), currentSystemTZ.String())
-	log.Printf("新时区当前时间: %s", testTime.Format("2006-01-02 15:04:05 MST"))
-	
+
+	configLog.Info("系统时区已热重载", "old", oldTZ.String(), "new", currentSystemTZ.String(),
+		"time", testTime.Format("2006-01-02 15:04:05 MST"))
+
 	return nil
 }
 
@@ -125,7 +229,7 @@ func (c *Client) reloadSystemTimezone() error {
 func (c *Client) now() time.Time {
 	c.tzMutex.RLock()
 	defer c.tzMutex.RUnlock()
-	return time.Now().In(c.currentTZ)
+	return time.Now().Add(c.clockOffset).In(c.currentTZ)
 }
 
 // configWatcher 配置文件监控器
@@ -141,12 +245,12 @@ func (c *Client) configWatcher() {
 			// 检查配置文件更新
 			if c.checkConfigUpdate() {
 				if err := c.reloadConfig(); err != nil {
-					log.Printf("重载配置失败: %v", err)
+					configLog.Error("重载配置失败", "error", err)
 				} else {
-					log.Printf("配置文件已重载")
+					configLog.Info("配置文件已重载")
 				}
 			}
-			
+
 			// 尝试重载系统时区
 			if err := c.reloadSystemTimezone(); err != nil {
 				// 静默处理时区重载错误，不影响主要功能
@@ -182,34 +286,57 @@ func (c *Client) reloadConfig() error {
 	if err != nil {
 		return fmt.Errorf("加载配置文件失败: %v", err)
 	}
+	if err := newConfig.Validate(); err != nil {
+		// 记录修改时间，文件再次修改前不重复尝试
+		c.updateConfigModTime()
+		return fmt.Errorf("配置无效，继续使用原配置:\n%v", err)
+	}
+
+	c.applyConfig(newConfig)
+	return nil
+}
 
+// applyConfig 替换当前配置（须已通过校验）并应用日志、网卡等变化
+func (c *Client) applyConfig(newConfig *models.ClientConfig) {
 	c.configMutex.Lock()
 	oldHostname := c.config.Hostname
 	oldServerURL := c.config.ServerURL
 	oldInterfaceName := c.config.InterfaceName
+	oldInterfaceFilter := c.config.InterfaceFilter
+	oldLog := c.config.Log
 
 	c.config = newConfig
 	c.configMutex.Unlock()
 
 	c.updateConfigModTime()
 
+	if newConfig.Log != oldLog {
+		if err := logging.Setup(newConfig.Log); err != nil {
+			configLog.Error("应用日志配置失败", "error", err)
+		} else {
+			configLog.Info("日志配置已更新", "level", newConfig.Log.Level, "format", newConfig.Log.Format)
+		}
+	}
+
 	// 记录重要配置变化
 	if newConfig.Hostname != oldHostname {
-		log.Printf("主机名已更新: %s -> %s", oldHostname, newConfig.Hostname)
+		configLog.Info("主机名已更新", "old", oldHostname, "new", newConfig.Hostname)
 	}
 	if newConfig.ServerURL != oldServerURL {
-		log.Printf("服务器地址已更新: %s -> %s", oldServerURL, newConfig.ServerURL)
+		configLog.Info("服务器地址已更新", "old", oldServerURL, "new", newConfig.ServerURL)
 	}
 	if newConfig.InterfaceName != oldInterfaceName {
-		log.Printf("网卡设置已更新: %s -> %s", oldInterfaceName, newConfig.InterfaceName)
+		configLog.Info("网卡设置已更新", "old", oldInterfaceName, "new", newConfig.InterfaceName)
 		// 网卡变更时重置统计缓存
-		c.lastNetStats = make(map[string]net.IOCountersStat)
+		c.netTracker.reset()
+		c.sampleTracker.reset()
 		// 更新网卡信息显示
-		interfaceInfo := c.getInterfaceInfo()
-		log.Printf("网卡配置: %s", interfaceInfo)
+		collectorLog.Info("网卡配置", "interfaces", c.getInterfaceInfo())
+	} else if !reflect.DeepEqual(newConfig.InterfaceFilter, oldInterfaceFilter) {
+		// 过滤规则变更时，新加入的网卡由计数跟踪自动建立基线
+		configLog.Info("网卡过滤规则已更新")
+		collectorLog.Info("网卡配置", "interfaces", c.getInterfaceInfo())
 	}
-
-	return nil
 }
 
 // 安全的配置访问方法
@@ -233,7 +360,14 @@ func (c *Client) getThresholdConfig() models.ClientThresholdConfig {
 
 func (c *Client) Stop() {
 	close(c.stopChan)
+	if c.scrapeServer != nil {
+		c.scrapeServer.Close()
+	}
+	if c.relayServer != nil {
+		c.relayServer.Close()
+	}
 	c.wg.Wait()
+	c.closeGRPC()
 }
 
 func (c *Client) reportMetrics() error {
@@ -244,27 +378,78 @@ func (c *Client) reportMetrics() error {
 
 	now := c.now()
 	effectiveThreshold := c.getEffectiveThresholdMbps(now)
-	
-	// 添加时区和阈值计算的详细日志
+
+	// 时区和阈值计算的详细信息仅在 debug 级别输出
 	zoneName, zoneOffset := now.Zone()
-	log.Printf("当前时间: %s, 时区: %s (UTC%+d), 当前阈值: %.2f Mbps", 
-		now.Format("2006-01-02 15:04:05"), zoneName, zoneOffset/3600, effectiveThreshold)
+	reportLog.Debug("计算当前阈值", "time", now.Format("2006-01-02 15:04:05"), "timezone", zoneName,
+		"utc_offset_hours", zoneOffset/3600, "threshold_mbps", effectiveThreshold)
+
+	// 接近或超过阈值时附带资源占用进程快照
+	if cfg := c.getTopProcessesConfig(); cfg.Enabled {
+		// 每次上报都采集以维护CPU时间基线
+		top, err := c.procTracker.collect(cfg.Count)
+		if err != nil {
+			collectorLog.Warn("采集进程快照失败", "error", err)
+		} else if c.shouldAttachTopProcesses(cfg, metrics, effectiveThreshold) {
+			metrics.TopProcesses = top
+		}
+	}
 
 	c.configMutex.RLock()
 	password := c.config.Password
 	hostname := c.config.Hostname
 	serverURL := c.config.ServerURL
+	tags := c.config.Tags
 	c.configMutex.RUnlock()
 
 	request := models.ReportRequest{
 		Password:               password,
+		NodeID:                 c.nodeID,
 		Hostname:               hostname,
-		Timestamp:              now.Unix(),
+		Tags:                   tags,
+		Timestamp:              time.Now().Unix(), // 使用未校正的本地时钟，服务端据此计算偏差
 		Metrics:                *metrics,
 		EffectiveThresholdMbps: effectiveThreshold,
+		SpeedTest:              c.takeSpeedTestResult(),
+		ConfigVersion:          c.getConfigVersion(),
+		Session:                c.session,
+		Seq:                    c.nextReportSeq(),
+	}
+
+	// 保存最新上报供抓取接口使用
+	c.setLatestReport(request)
+
+	transport, grpcAddress := c.getTransport()
+	if transport != models.TransportGRPC && serverURL == "" {
+		// 仅抓取模式：不主动上报，测速结果保留到服务端确认记录为止
+		c.restoreSpeedTestResult(request.SpeedTest)
+		if resp := c.takeScrapeResponse(); resp != nil {
+			c.applyServerInstructions(resp)
+		}
+		return nil
+	}
+
+	var sendErr error
+	batchSize, batchMaxDelay, compression := c.getBatchConfig()
+	switch {
+	case c.relayServer != nil:
+		// 中继模式下本机上报与缓存的本地上报一起通过HTTP批量发送
+		sendErr = c.forwardRelayReports(request, serverURL, password, compression)
+	case transport == models.TransportGRPC:
+		sendErr = c.sendReportGRPC(request, grpcAddress)
+	case batchSize > 1:
+		// 批量模式下发送失败的上报保留在队列中，不需要回退测速结果
+		return c.queueBatchReport(request, serverURL, password, batchSize, batchMaxDelay, compression)
+	default:
+		sendErr = c.sendReport(request, serverURL, compression)
+	}
+
+	if err := sendErr; err != nil {
+		c.restoreSpeedTestResult(request.SpeedTest)
+		return err
 	}
 
-	return c.sendReport(request, serverURL)
+	return nil
 }
 
 func (c *Client) collectMetrics() (*models.SystemMetrics, error) {
@@ -287,9 +472,9 @@ func (c *Client) collectMetrics() (*models.SystemMetrics, error) {
 	}
 
 	// 网络速率
-	netInBps, netOutBps, err := c.getNetworkSpeed()
+	netInBps, netOutBps, interfaces, err := c.getNetworkSpeed()
 	if err != nil {
-		log.Printf("获取网络速率失败: %v", err)
+		collectorLog.Warn("获取网络速率失败", "error", err)
 		netInBps, netOutBps = 0, 0
 	}
 
@@ -300,136 +485,98 @@ func (c *Client) collectMetrics() (*models.SystemMetrics, error) {
 		NetworkInBps:  netInBps,
 		NetworkOutBps: netOutBps,
 		UptimeSeconds: uptime,
+		Stats:         c.takeIntervalStats(),
+		Interfaces:    interfaces,
 	}
 
 	return metrics, nil
 }
 
-func (c *Client) getNetworkSpeed() (uint64, uint64, error) {
+func (c *Client) getNetworkSpeed() (uint64, uint64, []models.InterfaceStatus, error) {
+	stats, err := c.selectInterfaceCounters()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// 每个网卡独立计算速率；首次出现或计数器重置的网卡本次记为0（避免冷启动高估）
+	inBps, outBps, statuses, _ := c.netTracker.update(stats, time.Now())
+
+	return uint64(inBps), uint64(outBps), statuses, nil
+}
+
+// selectInterfaceCounters 读取参与统计的各网卡累计计数器
+func (c *Client) selectInterfaceCounters() ([]net.IOCountersStat, error) {
 	// 获取每个网卡的统计
 	stats, err := net.IOCounters(true)
 	if err != nil || len(stats) == 0 {
-		return 0, 0, err
+		return nil, err
 	}
 
-	var currentStats net.IOCountersStat
-	var currentInBytes, currentOutBytes uint64
-	var interfacesUsed []string
-
 	interfaceName := c.getInterfaceName()
 
 	// 如果指定了网卡名称，使用指定网卡
 	if interfaceName != "" {
-		found := false
 		for _, s := range stats {
 			if s.Name == interfaceName {
-				currentStats = s
-				currentInBytes = s.BytesRecv
-				currentOutBytes = s.BytesSent
-				interfacesUsed = []string{s.Name}
-				found = true
-				break
+				return []net.IOCountersStat{s}, nil
 			}
 		}
-		if !found {
-			return 0, 0, fmt.Errorf("指定的网卡 %s 未找到", interfaceName)
-		}
-	} else {
-		// 默认情况：统计所有非回环和非虚拟网卡的总和
-		for _, s := range stats {
-			if s.Name != "lo" && !isVirtualName(s.Name) {
-				currentInBytes += s.BytesRecv
-				currentOutBytes += s.BytesSent
-				interfacesUsed = append(interfacesUsed, s.Name)
-			}
-		}
-
-		if len(interfacesUsed) == 0 {
-			return 0, 0, fmt.Errorf("未找到可用的物理网卡")
-		}
-
-		// 为总和创建虚拟统计结构
-		currentStats = net.IOCountersStat{
-			Name:      "total", // 使用特殊标识符
-			BytesRecv: currentInBytes,
-			BytesSent: currentOutBytes,
-		}
+		return nil, fmt.Errorf("指定的网卡 %s 未找到", interfaceName)
 	}
 
-	// 生成统计键名
-	statsKey := c.getStatsKey(interfacesUsed, interfaceName)
-
-	// 如果是第一次采集，记录并返回0（避免冷启动高估）
-	lastStats, exists := c.lastNetStats[statsKey]
-	now := time.Now()
-	if !exists {
-		c.lastNetStats[statsKey] = currentStats
-		c.lastSampleAt = now
-		return 0, 0, nil
-	}
+	// 默认情况：按 include/exclude 规则统计网卡总和
+	selected, _ := filterInterfaces(stats, c.getInterfaceFilter())
 
-	// 用真实间隔计算速度
-	elapsed := now.Sub(c.lastSampleAt).Seconds()
-	if elapsed <= 0 {
-		elapsed = float64(c.getReportInterval())
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("未找到可用的物理网卡")
 	}
 
-	bytesInDiff := currentStats.BytesRecv - lastStats.BytesRecv
-	bytesOutDiff := currentStats.BytesSent - lastStats.BytesSent
-
-	inBps := uint64(float64(bytesInDiff) / elapsed)
-	outBps := uint64(float64(bytesOutDiff) / elapsed)
+	return selected, nil
+}
 
-	c.lastNetStats[statsKey] = currentStats
-	c.lastSampleAt = now
+func (c *Client) sendReport(request models.ReportRequest, serverURL, compression string) error {
+	var reportResp models.ReportResponse
+	url := fmt.Sprintf("%s/api/report", serverURL)
+	if err := c.postJSON(url, request, compression, &reportResp); err != nil {
+		return err
+	}
 
-	return inBps, outBps, nil
+	c.handleReportResponse(request, &reportResp)
+	return nil
 }
 
-// getStatsKey 生成统计键名
-func (c *Client) getStatsKey(interfaces []string, interfaceName string) string {
-	if interfaceName != "" {
-		return interfaceName
-	}
-	// 对于总和统计，使用所有网卡名称组合
-	if len(interfaces) == 0 {
-		return "total"
-	}
-	return fmt.Sprintf("total_%s", strings.Join(interfaces, "_"))
+// handleReportResponse 处理上报响应中的服务端指令并记录上报结果（HTTP与gRPC共用）
+func (c *Client) handleReportResponse(request models.ReportRequest, reportResp *models.ReportResponse) {
+	c.applyServerInstructions(reportResp)
+
+	reportLog.Info("上报成功",
+		"cpu_percent", request.Metrics.CPUPercent,
+		"memory_used", formatBytes(request.Metrics.MemoryUsed),
+		"memory_total", formatBytes(request.Metrics.MemoryTotal),
+		"net_in_mbps", float64(request.Metrics.NetworkInBps)/125000.0,
+		"net_out_mbps", float64(request.Metrics.NetworkOutBps)/125000.0,
+		"threshold_mbps", request.EffectiveThresholdMbps,
+	)
 }
 
-func (c *Client) sendReport(request models.ReportRequest, serverURL string) error {
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return fmt.Errorf("JSON编码失败: %v", err)
-	}
+// applyServerInstructions 执行服务端响应中的指令（主动上报与抓取回传共用）
+func (c *Client) applyServerInstructions(reportResp *models.ReportResponse) {
+	c.topProcessesRequested = reportResp.TopProcessesRequested
 
-	url := fmt.Sprintf("%s/api/report", serverURL)
-	resp, err := c.httpClient.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("HTTP请求失败: %v", err)
+	if reportResp.ServerTime > 0 {
+		c.updateClockOffset(reportResp.ServerTime)
 	}
-	defer resp.Body.Close()
 
-	var response models.APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return fmt.Errorf("响应解析失败: %v", err)
+	if reportResp.Config != nil {
+		if err := c.applyPushedConfig(reportResp.Config); err != nil {
+			configLog.Error("应用服务端下发配置失败", "error", err)
+		}
 	}
 
-	if !response.Success {
-		return fmt.Errorf("服务器返回错误: %s", response.Message)
+	if reportResp.SpeedTestRequested {
+		reportLog.Info("收到服务端测速请求")
+		c.startSpeedTest()
 	}
-
-	log.Printf("上报成功 - CPU: %.1f%%, 内存: %s/%s, 网络: ↓%.2fMbps ↑%.2fMbps (阈值: %.2fMbps)",
-		request.Metrics.CPUPercent,
-		formatBytes(request.Metrics.MemoryUsed),
-		formatBytes(request.Metrics.MemoryTotal),
-		float64(request.Metrics.NetworkInBps)/125000.0,
-		float64(request.Metrics.NetworkOutBps)/125000.0,
-		request.EffectiveThresholdMbps,
-	)
-
-	return nil
 }
 
 func formatBytes(bytes uint64) string {
@@ -445,42 +592,37 @@ func formatBytes(bytes uint64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// getInterfaceInfo 获取网卡配置信息用于日志显示
+// getInterfaceInfo 获取网卡配置信息用于日志显示，并逐个记录网卡的选择原因
 func (c *Client) getInterfaceInfo() string {
 	interfaceName := c.getInterfaceName()
 	if interfaceName != "" {
 		return fmt.Sprintf("指定网卡 %s", interfaceName)
 	}
 
-	// 获取所有可用的物理网卡
 	stats, err := net.IOCounters(true)
 	if err != nil {
 		return "网卡信息获取失败"
 	}
 
-	var physicalIfaces []string
-	for _, s := range stats {
-		if s.Name != "lo" && !isVirtualName(s.Name) {
-			physicalIfaces = append(physicalIfaces, s.Name)
+	selected, decisions := filterInterfaces(stats, c.getInterfaceFilter())
+	for _, d := range decisions {
+		if d.Selected {
+			collectorLog.Info("网卡参与统计", "interface", d.Name, "reason", d.Reason)
+		} else {
+			collectorLog.Info("网卡跳过", "interface", d.Name, "reason", d.Reason)
 		}
 	}
 
-	if len(physicalIfaces) == 0 {
-		return "未找到可用的物理网卡"
+	if len(selected) == 0 {
+		return "未找到可用的网卡"
 	}
 
-	return fmt.Sprintf("自动统计所有物理网卡总和: %s", strings.Join(physicalIfaces, ", "))
-}
-
-func isVirtualName(name string) bool {
-	lower := strings.ToLower(name)
-	prefixes := []string{"veth", "docker", "br-", "virbr", "vmnet", "zt", "tailscale", "wg"}
-	for _, p := range prefixes {
-		if strings.HasPrefix(lower, p) {
-			return true
-		}
+	var names []string
+	for _, s := range selected {
+		names = append(names, s.Name)
 	}
-	return false
+
+	return fmt.Sprintf("自动统计网卡总和: %s", strings.Join(names, ", "))
 }
 
 // getEffectiveThresholdMbps 计算当前时间的有效阈值（动态优先，fallback到静态）
@@ -496,41 +638,29 @@ func (c *Client) getEffectiveThresholdMbps(now time.Time) float64 {
 			}
 		}
 	}
-	
+
 	// 静态阈值
 	if thresholdConfig.StaticBandwidthMbps > 0 {
 		return thresholdConfig.StaticBandwidthMbps
 	}
-	
+
 	return 0
 }
 
 func inWindow(now time.Time, startHHMM, endHHMM string) bool {
-	start, ok1 := parseHHMM(startHHMM)
-	end, ok2 := parseHHMM(endHHMM)
+	start, ok1 := models.ParseHHMM(startHHMM)
+	end, ok2 := models.ParseHHMM(endHHMM)
 	if !ok1 || !ok2 {
 		return false
 	}
-	
+
 	mins := now.Hour()*60 + now.Minute()
-	
+
 	if start <= end {
 		// 同一天内的时间窗口，如 09:00-22:00
 		return mins >= start && mins < end
 	} else {
-		// 跨午夜窗口，例如 22:00-02:00  
+		// 跨午夜窗口，例如 22:00-02:00
 		return mins >= start || mins < end
 	}
 }
-
-func parseHHMM(s string) (int, bool) {
-	if len(s) != 5 || s[2] != ':' {
-		return 0, false
-	}
-	h := int(s[0]-'0')*10 + int(s[1]-'0')
-	m := int(s[3]-'0')*10 + int(s[4]-'0')
-	if h < 0 || h > 23 || m < 0 || m > 59 {
-		return 0, false
-	}
-	return h*60 + m, true
-}
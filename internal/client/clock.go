@@ -0,0 +1,45 @@
+package client
+
+import (
+	"time"
+)
+
+// 时钟偏差达到该值时记录日志并（启用校正时）应用到本地时间；上报时间戳精度为秒，更小的偏差视为误差
+const clockSkewMinimum = 2 * time.Second
+
+// updateClockOffset 根据服务端返回的时间更新时钟偏差
+func (c *Client) updateClockOffset(serverTime int64) {
+	offset := time.Unix(serverTime, 0).Sub(time.Now().Truncate(time.Second))
+	if offset > -clockSkewMinimum && offset < clockSkewMinimum {
+		offset = 0
+	}
+
+	c.configMutex.RLock()
+	correction := c.config.ClockCorrection
+	c.configMutex.RUnlock()
+
+	c.tzMutex.Lock()
+	defer c.tzMutex.Unlock()
+
+	// 偏差变化时才记录，避免每次上报重复输出
+	if offset != c.clockSkew {
+		if offset == 0 {
+			configLog.Info("本地时钟与服务端一致")
+		} else {
+			configLog.Warn("本地时钟与服务端存在偏差（本地偏慢为正）", "offset_seconds", offset.Seconds())
+		}
+		c.clockSkew = offset
+	}
+
+	if !correction {
+		offset = 0
+	}
+	if offset != c.clockOffset {
+		if offset != 0 {
+			configLog.Info("已按服务端时间校正本地时间", "offset_seconds", offset.Seconds())
+		} else if c.clockOffset != 0 {
+			configLog.Info("已取消本地时间校正")
+		}
+		c.clockOffset = offset
+	}
+}
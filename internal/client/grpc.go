@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func (c *Client) getTransport() (string, string) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.Transport, c.config.GRPCAddress
+}
+
+func (c *Client) getGRPCTLS() models.GRPCTLSConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.GRPCTLS
+}
+
+// grpcCredentials 按 grpc_tls 设置选择传输凭证，非回环地址默认使用TLS
+func grpcCredentials(address string, config models.GRPCTLSConfig) (credentials.TransportCredentials, error) {
+	if !config.Enabled(address) {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig := &tls.Config{ServerName: config.ServerName, MinVersion: tls.VersionTLS12}
+	if config.CAFile != "" {
+		pool, err := models.LoadCertPool(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// sendReportGRPC 通过gRPC长连接发送上报并等待对应响应，出错时关闭连接以便下次重连
+func (c *Client) sendReportGRPC(request models.ReportRequest, address string) error {
+	if address == "" {
+		return fmt.Errorf("未配置gRPC服务地址")
+	}
+
+	if err := c.ensureGRPCStream(address); err != nil {
+		c.closeGRPC()
+		return fmt.Errorf("gRPC连接失败: %v", err)
+	}
+
+	if err := c.grpcStream.Send(&request); err != nil {
+		c.closeGRPC()
+		return fmt.Errorf("gRPC发送失败: %v", err)
+	}
+
+	type result struct {
+		resp *models.ReportResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	stream := c.grpcStream
+	go func() {
+		resp, err := stream.Recv()
+		done <- result{resp, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			c.closeGRPC()
+			return fmt.Errorf("gRPC响应失败: %v", r.err)
+		}
+		c.handleReportResponse(request, r.resp)
+		return nil
+	case <-time.After(c.httpClient.Timeout):
+		c.closeGRPC()
+		return fmt.Errorf("gRPC响应超时")
+	}
+}
+
+// ensureGRPCStream 建立（或在地址、TLS设置变更后重建）上报流
+func (c *Client) ensureGRPCStream(address string) error {
+	tlsConfig := c.getGRPCTLS()
+	if c.grpcStream != nil && c.grpcAddress == address && c.grpcTLS == tlsConfig {
+		return nil
+	}
+	c.closeGRPC()
+
+	creds, err := grpcCredentials(address, tlsConfig)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := rpc.NewMonitorClient(conn).Report(ctx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return err
+	}
+
+	c.grpcConn = conn
+	c.grpcStream = stream
+	c.grpcCancel = cancel
+	c.grpcAddress = address
+	c.grpcTLS = tlsConfig
+	return nil
+}
+
+// closeGRPC 关闭上报流与连接
+func (c *Client) closeGRPC() {
+	if c.grpcCancel != nil {
+		c.grpcCancel()
+	}
+	if c.grpcConn != nil {
+		c.grpcConn.Close()
+	}
+	c.grpcConn = nil
+	c.grpcStream = nil
+	c.grpcCancel = nil
+	c.grpcAddress = ""
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func (c *Client) getHeartbeatConfig() (address string, interval int, hostname, password string) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.HeartbeatAddress, c.config.HeartbeatIntervalSeconds, c.config.Hostname, c.config.Password
+}
+
+// heartbeatLoop 按间隔向服务端发送UDP心跳，配置修改后下一轮生效
+func (c *Client) heartbeatLoop() {
+	defer c.wg.Done()
+
+	failing := false
+	for {
+		address, interval, hostname, password := c.getHeartbeatConfig()
+
+		wait := 5 * time.Second // 未启用时定期检查配置
+		if address != "" && interval > 0 {
+			wait = time.Duration(interval) * time.Second
+
+			if err := sendHeartbeat(address, c.nodeID, hostname, password); err != nil {
+				// 仅在首次失败时记录，避免刷屏
+				if !failing {
+					reportLog.Warn("发送UDP心跳失败", "error", err)
+				}
+				failing = true
+			} else if failing {
+				reportLog.Info("UDP心跳已恢复")
+				failing = false
+			}
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+func sendHeartbeat(address, nodeID, hostname, password string) error {
+	timestamp := time.Now().UnixNano()
+	data, err := json.Marshal(models.Heartbeat{
+		NodeID:    nodeID,
+		Hostname:  hostname,
+		Timestamp: timestamp,
+		Signature: models.HeartbeatSignature(password, nodeID, hostname, timestamp),
+	})
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("udp", address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(data)
+	return err
+}
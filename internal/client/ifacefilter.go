@@ -0,0 +1,126 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"bandwidth-monitor/internal/models"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// sysClassNet Linux 网卡属性目录
+const sysClassNet = "/sys/class/net"
+
+// interfaceDecision 单个网卡的选择结果及原因
+type interfaceDecision struct {
+	Name     string
+	Selected bool
+	Reason   string
+}
+
+func (c *Client) getInterfaceFilter() models.InterfaceFilterConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.InterfaceFilter
+}
+
+// filterInterfaces 按 include/exclude 规则和 /sys/class/net 属性筛选网卡
+func filterInterfaces(stats []net.IOCountersStat, filter models.InterfaceFilterConfig) ([]net.IOCountersStat, []interfaceDecision) {
+	var selected []net.IOCountersStat
+	var decisions []interfaceDecision
+
+	for _, s := range stats {
+		ok, reason := matchInterface(s.Name, filter)
+		decisions = append(decisions, interfaceDecision{Name: s.Name, Selected: ok, Reason: reason})
+		if ok {
+			selected = append(selected, s)
+		}
+	}
+
+	return selected, decisions
+}
+
+// matchInterface 判断网卡是否参与统计：先匹配 include（为空表示全部），再排除 exclude，最后检查属性
+func matchInterface(name string, filter models.InterfaceFilterConfig) (bool, string) {
+	if len(filter.Include) > 0 {
+		pattern, err := matchAny(name, filter.Include)
+		if err != nil {
+			return false, err.Error()
+		}
+		if pattern == "" {
+			return false, "不匹配任何 include 规则"
+		}
+	}
+
+	pattern, err := matchAny(name, filter.Exclude)
+	if err != nil {
+		return false, err.Error()
+	}
+	if pattern != "" {
+		return false, fmt.Sprintf("匹配 exclude 规则 %q", pattern)
+	}
+
+	// 非 Linux 系统没有 /sys/class/net，属性过滤不生效
+	dir := filepath.Join(sysClassNet, name)
+	if _, err := os.Stat(dir); err != nil {
+		return true, "通过名称规则"
+	}
+
+	if filter.PhysicalOnly && !pathExists(filepath.Join(dir, "device")) {
+		return false, "不是物理设备"
+	}
+	if filter.ExcludeBridgeMembers && pathExists(filepath.Join(dir, "brport")) {
+		return false, "是网桥成员"
+	}
+	if filter.RequireUp {
+		state := readSysAttr(dir, "operstate")
+		if state != "up" && state != "unknown" {
+			return false, fmt.Sprintf("operstate 为 %s", state)
+		}
+	}
+
+	return true, "通过名称与属性规则"
+}
+
+// matchAny 返回第一个匹配的规则；以 "re:" 开头的规则按正则匹配，其余按不区分大小写的 glob 匹配
+func matchAny(name string, patterns []string) (string, error) {
+	for _, p := range patterns {
+		if expr, ok := strings.CutPrefix(p, "re:"); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return "", fmt.Errorf("正则规则 %q 无效: %v", p, err)
+			}
+			if re.MatchString(name) {
+				return p, nil
+			}
+			continue
+		}
+
+		matched, err := path.Match(strings.ToLower(p), strings.ToLower(name))
+		if err != nil {
+			return "", fmt.Errorf("glob 规则 %q 无效: %v", p, err)
+		}
+		if matched {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+func pathExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+func readSysAttr(dir, attr string) string {
+	data, err := os.ReadFile(filepath.Join(dir, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func TestMatchInterface(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  models.InterfaceFilterConfig
+		iface   string
+		want    bool
+		wantWhy string
+	}{
+		{"无规则", models.InterfaceFilterConfig{}, "eth0", true, ""},
+		{"include 匹配", models.InterfaceFilterConfig{Include: []string{"eth*"}}, "eth0", true, ""},
+		{"include 不匹配", models.InterfaceFilterConfig{Include: []string{"eth*"}}, "ens3", false, "不匹配任何 include 规则"},
+		{"exclude 优先于 include", models.InterfaceFilterConfig{Include: []string{"eth*"}, Exclude: []string{"eth1"}}, "eth1", false, `匹配 exclude 规则 "eth1"`},
+		{"glob 不区分大小写", models.InterfaceFilterConfig{Exclude: []string{"DOCKER*"}}, "docker0", false, "exclude"},
+		{"正则规则", models.InterfaceFilterConfig{Include: []string{"re:^en(p|s)[0-9]+"}}, "enp3s0", true, ""},
+		{"正则区分大小写", models.InterfaceFilterConfig{Include: []string{"re:^eth"}}, "ETH0", false, "include"},
+		{"无效的正则", models.InterfaceFilterConfig{Exclude: []string{"re:("}}, "eth0", false, "正则规则"},
+		{"无效的 glob", models.InterfaceFilterConfig{Include: []string{"eth["}}, "eth0", false, "glob 规则"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, why := matchInterface(tt.iface, tt.filter)
+			if got != tt.want || !strings.Contains(why, tt.wantWhy) {
+				t.Errorf("matchInterface(%q) = %v, %q，期望 %v（原因包含 %q）", tt.iface, got, why, tt.want, tt.wantWhy)
+			}
+		})
+	}
+}
+
+// baselineExcluded 引入网卡过滤前按名称跳过网卡的判断
+func baselineExcluded(name string) bool {
+	if name == "lo" {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, p := range []string{"veth", "docker", "br-", "virbr", "vmnet", "zt", "tailscale", "wg"} {
+		if strings.HasPrefix(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDefaultInterfaceExcludes(t *testing.T) {
+	names := []string{
+		"lo", "eth0", "ens3", "enp3s0", "bond0", "br0", "br-1a2b3c", "docker0", "Docker1", "veth12ab",
+		"virbr0", "vmnet8", "zt5u4y", "ztabc", "tailscale0", "wg0", "WG-home", "tun0", "lo0", "wlan0",
+	}
+	for _, name := range names {
+		pattern, err := matchAny(name, models.DefaultInterfaceExcludes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if excluded := pattern != ""; excluded != baselineExcluded(name) {
+			t.Errorf("%s: 默认规则排除 = %v，原有行为为 %v", name, excluded, baselineExcluded(name))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// ifaceCounter 单个网卡的上次计数
+type ifaceCounter struct {
+	stats net.IOCountersStat
+	at    time.Time
+}
+
+// netTracker 按网卡独立跟踪累计计数器，处理计数器重置/回绕以及网卡增删
+type netTracker struct {
+	name   string // 用于日志区分上报与子采样
+	mutex  sync.Mutex
+	ifaces map[string]*ifaceCounter
+}
+
+func newNetTracker(name string) *netTracker {
+	return &netTracker{
+		name:   name,
+		ifaces: make(map[string]*ifaceCounter),
+	}
+}
+
+// reset 清空所有网卡基线（网卡配置变更时使用）
+func (t *netTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.ifaces = make(map[string]*ifaceCounter)
+}
+
+// update 用本次计数更新各网卡状态，返回有效网卡的速率总和与每个网卡的状态。
+// valid 为 false 表示没有任何网卡产生有效速率（例如首次采样）。
+func (t *netTracker) update(stats []net.IOCountersStat, now time.Time) (inBps, outBps float64, statuses []models.InterfaceStatus, valid bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	initial := len(t.ifaces) == 0
+	seen := make(map[string]bool, len(stats))
+	for _, s := range stats {
+		seen[s.Name] = true
+		status := models.InterfaceStatus{Name: s.Name}
+
+		last, exists := t.ifaces[s.Name]
+		switch {
+		case !exists:
+			// 新出现的网卡只记录基线，避免把历史累计值算作速率
+			status.Status = models.InterfaceStatusNew
+			if !initial {
+				collectorLog.Info("网卡出现", "tracker", t.name, "interface", s.Name)
+			}
+		case s.BytesRecv < last.stats.BytesRecv || s.BytesSent < last.stats.BytesSent:
+			// 计数器变小说明网卡被重建、驱动重载或计数器回绕，本次丢弃并重建基线
+			status.Status = models.InterfaceStatusReset
+			collectorLog.Warn("网卡计数器重置", "tracker", t.name, "interface", s.Name,
+				"recv_before", last.stats.BytesRecv, "recv_after", s.BytesRecv,
+				"sent_before", last.stats.BytesSent, "sent_after", s.BytesSent)
+		default:
+			elapsed := now.Sub(last.at).Seconds()
+			if elapsed <= 0 {
+				status.Status = models.InterfaceStatusNew
+				break
+			}
+			status.Status = models.InterfaceStatusOK
+			status.InBps = uint64(float64(s.BytesRecv-last.stats.BytesRecv) / elapsed)
+			status.OutBps = uint64(float64(s.BytesSent-last.stats.BytesSent) / elapsed)
+			inBps += float64(status.InBps)
+			outBps += float64(status.OutBps)
+			valid = true
+		}
+
+		t.ifaces[s.Name] = &ifaceCounter{stats: s, at: now}
+		statuses = append(statuses, status)
+	}
+
+	// 上次存在、本次消失的网卡
+	for name := range t.ifaces {
+		if !seen[name] {
+			delete(t.ifaces, name)
+			collectorLog.Info("网卡消失", "tracker", t.name, "interface", name)
+			statuses = append(statuses, models.InterfaceStatus{Name: name, Status: models.InterfaceStatusGone})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return inBps, outBps, statuses, valid
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestNetTrackerUpdate(t *testing.T) {
+	counter := func(name string, recv, sent uint64) net.IOCountersStat {
+		return net.IOCountersStat{Name: name, BytesRecv: recv, BytesSent: sent}
+	}
+	type step struct {
+		stats    []net.IOCountersStat
+		inBps    float64
+		valid    bool
+		statuses map[string]string
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"正常递增", []step{
+			{[]net.IOCountersStat{counter("eth0", 1000, 500)}, 0, false, map[string]string{"eth0": models.InterfaceStatusNew}},
+			{[]net.IOCountersStat{counter("eth0", 3000, 1500)}, 2000, true, map[string]string{"eth0": models.InterfaceStatusOK}},
+			{[]net.IOCountersStat{counter("eth0", 3000, 1500)}, 0, true, map[string]string{"eth0": models.InterfaceStatusOK}},
+		}},
+		{"计数器归零", []step{
+			{[]net.IOCountersStat{counter("eth0", 1<<40, 1<<30)}, 0, false, map[string]string{"eth0": models.InterfaceStatusNew}},
+			{[]net.IOCountersStat{counter("eth0", 0, 0)}, 0, false, map[string]string{"eth0": models.InterfaceStatusReset}},
+			{[]net.IOCountersStat{counter("eth0", 500, 100)}, 500, true, map[string]string{"eth0": models.InterfaceStatusOK}},
+		}},
+		{"32位计数器回绕", []step{
+			{[]net.IOCountersStat{counter("eth0", math.MaxUint32-100, 10)}, 0, false, map[string]string{"eth0": models.InterfaceStatusNew}},
+			{[]net.IOCountersStat{counter("eth0", 200, 20)}, 0, false, map[string]string{"eth0": models.InterfaceStatusReset}},
+			{[]net.IOCountersStat{counter("eth0", 1200, 30)}, 1000, true, map[string]string{"eth0": models.InterfaceStatusOK}},
+		}},
+		{"仅发送计数回绕", []step{
+			{[]net.IOCountersStat{counter("eth0", 100, math.MaxUint32)}, 0, false, map[string]string{"eth0": models.InterfaceStatusNew}},
+			{[]net.IOCountersStat{counter("eth0", 200, 5)}, 0, false, map[string]string{"eth0": models.InterfaceStatusReset}},
+		}},
+		{"网卡消失后重新出现", []step{
+			{[]net.IOCountersStat{counter("eth0", 1000, 0), counter("wg0", 1000, 0)}, 0, false,
+				map[string]string{"eth0": models.InterfaceStatusNew, "wg0": models.InterfaceStatusNew}},
+			{[]net.IOCountersStat{counter("eth0", 2000, 0)}, 1000, true,
+				map[string]string{"eth0": models.InterfaceStatusOK, "wg0": models.InterfaceStatusGone}},
+			// 重建后的网卡计数从头开始，只记录基线，不把累计值算作速率
+			{[]net.IOCountersStat{counter("eth0", 3000, 0), counter("wg0", 1<<35, 0)}, 1000, true,
+				map[string]string{"eth0": models.InterfaceStatusOK, "wg0": models.InterfaceStatusNew}},
+			{[]net.IOCountersStat{counter("eth0", 4000, 0), counter("wg0", 1<<35+4000, 0)}, 5000, true,
+				map[string]string{"eth0": models.InterfaceStatusOK, "wg0": models.InterfaceStatusOK}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newNetTracker("测试")
+			now := time.Unix(1700000000, 0)
+			for i, step := range tt.steps {
+				now = now.Add(time.Second)
+				inBps, outBps, statuses, valid := tracker.update(step.stats, now)
+
+				if valid != step.valid || inBps != step.inBps {
+					t.Errorf("第 %d 步: inBps = %v, valid = %v，期望 %v, %v", i, inBps, valid, step.inBps, step.valid)
+				}
+				// 1秒内的速率不可能超过累计值的合理增量，回绕或重置时不得产生巨大的速率
+				if inBps < 0 || outBps < 0 || inBps > 1<<34 || outBps > 1<<34 {
+					t.Errorf("第 %d 步: 速率异常 in=%v out=%v", i, inBps, outBps)
+				}
+				if len(statuses) != len(step.statuses) {
+					t.Fatalf("第 %d 步: 网卡状态 %+v，期望 %v", i, statuses, step.statuses)
+				}
+				for _, status := range statuses {
+					if want := step.statuses[status.Name]; status.Status != want {
+						t.Errorf("第 %d 步: %s 状态为 %s，期望 %s", i, status.Name, status.Status, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNetTrackerSameTimestamp(t *testing.T) {
+	tracker := newNetTracker("测试")
+	now := time.Now()
+	tracker.update([]net.IOCountersStat{{Name: "eth0", BytesRecv: 100}}, now)
+	inBps, _, statuses, valid := tracker.update([]net.IOCountersStat{{Name: "eth0", BytesRecv: 200}}, now)
+	if valid || inBps != 0 || statuses[0].Status != models.InterfaceStatusNew {
+		t.Errorf("时间未前进时不应计算速率: in=%v valid=%v %+v", inBps, valid, statuses)
+	}
+}
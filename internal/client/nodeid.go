@@ -0,0 +1,63 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 机器标识文件，按顺序读取
+var machineIDPaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// nodeIDFile 无机器标识时生成的节点标识文件名，保存在配置文件同目录
+const nodeIDFile = "node-id"
+
+// loadNodeID 获取稳定的节点标识：优先由 machine-id 派生，否则读取或生成配置目录下的 node-id 文件
+func loadNodeID(configPath string) (string, error) {
+	for _, path := range machineIDPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if id := strings.TrimSpace(string(data)); id != "" {
+			// machine-id 不应直接对外暴露，按应用派生后使用
+			mac := hmac.New(sha256.New, []byte(id))
+			mac.Write([]byte("bandwidth-monitor"))
+			return hex.EncodeToString(mac.Sum(nil)[:16]), nil
+		}
+	}
+
+	path := filepath.Join(filepath.Dir(configPath), nodeIDFile)
+	if data, err := os.ReadFile(path); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("保存节点标识失败: %v", err)
+	}
+	configLog.Info("已生成节点标识", "node_id", id, "path", path)
+
+	return id, nil
+}
+
+// newUUID 生成随机UUID（v4）
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
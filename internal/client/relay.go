@@ -0,0 +1,251 @@
+package client
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"bandwidth-monitor/internal/compress"
+	"bandwidth-monitor/internal/models"
+)
+
+// 中继最多缓存的待转发上报条数，超出时丢弃最旧的上报
+const maxRelayBuffered = 1000
+
+// passwordMatches 以固定时间比较密码，避免通过响应时间逐字节猜测
+func passwordMatches(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func (c *Client) getRelayConfig() (models.RelayConfig, string) {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+
+	relay := c.config.Relay
+	if relay.Password == "" {
+		relay.Password = c.config.Password
+	}
+	return relay, c.config.ServerURL
+}
+
+// startRelayServer 启动中继接口：接收本地客户端的上报，随本机上报批量转发到服务端
+func (c *Client) startRelayServer() error {
+	relay, serverURL := c.getRelayConfig()
+	if relay.Listen == "" {
+		return nil
+	}
+	if serverURL == "" {
+		return fmt.Errorf("中继模式需要配置 server_url")
+	}
+
+	upstream, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("server_url 格式错误: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/report", c.handleRelayReport)
+	mux.HandleFunc("/api/report/batch", c.handleRelayBatch)
+	// 测速流量直接代理到服务端，测得的是 本地客户端 -> 中继 -> 服务端 的链路容量
+	mux.Handle("/api/speedtest/", c.relaySpeedTestProxy(upstream))
+
+	c.relayResponses = make(map[string]*models.ReportResponse)
+	c.relayServer = &http.Server{Addr: relay.Listen, Handler: mux}
+
+	go func() {
+		reportLog.Info("中继接口已启动", "listen", relay.Listen, "upstream", serverURL)
+		if err := c.relayServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			reportLog.Error("中继接口退出", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// relaySpeedTestProxy 校验本地密码后将测速请求以本机密码代理到服务端
+func (c *Client) relaySpeedTestProxy(upstream *url.URL) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		relay, _ := c.getRelayConfig()
+		if !passwordMatches(r.Header.Get("X-Password"), relay.Password) {
+			sendRelayResponse(w, http.StatusUnauthorized, false, "密码错误", nil)
+			return
+		}
+
+		c.configMutex.RLock()
+		r.Header.Set("X-Password", c.config.Password)
+		c.configMutex.RUnlock()
+
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+func sendRelayResponse(w http.ResponseWriter, status int, success bool, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.APIResponse{Success: success, Message: message, Data: data})
+}
+
+// handleRelayReport 接收本地客户端的单条上报；响应中带回服务端上一轮给该节点的指令
+func (c *Client) handleRelayReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendRelayResponse(w, http.StatusMethodNotAllowed, false, "仅支持POST方法", nil)
+		return
+	}
+
+	var req models.ReportRequest
+	if err := compress.DecodeJSONBody(r, &req); err != nil {
+		sendRelayResponse(w, http.StatusBadRequest, false, "JSON解析失败", nil)
+		return
+	}
+
+	relay, _ := c.getRelayConfig()
+	if !passwordMatches(req.Password, relay.Password) {
+		sendRelayResponse(w, http.StatusUnauthorized, false, "密码错误", nil)
+		return
+	}
+	if req.Hostname == "" {
+		sendRelayResponse(w, http.StatusBadRequest, false, "缺少主机名", nil)
+		return
+	}
+
+	c.bufferRelayReports([]models.ReportRequest{req})
+
+	resp := c.takeRelayResponse(req.NodeKey())
+	if resp == nil {
+		resp = &models.ReportResponse{}
+	}
+	sendRelayResponse(w, http.StatusOK, true, "上报成功", resp)
+}
+
+// handleRelayBatch 接收本地客户端的批量上报
+func (c *Client) handleRelayBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendRelayResponse(w, http.StatusMethodNotAllowed, false, "仅支持POST方法", nil)
+		return
+	}
+
+	var batch models.BatchReportRequest
+	if err := compress.DecodeJSONBody(r, &batch); err != nil {
+		sendRelayResponse(w, http.StatusBadRequest, false, "JSON解析失败: "+err.Error(), nil)
+		return
+	}
+
+	relay, _ := c.getRelayConfig()
+	if !passwordMatches(batch.Password, relay.Password) {
+		sendRelayResponse(w, http.StatusUnauthorized, false, "密码错误", nil)
+		return
+	}
+
+	result := &models.BatchReportResponse{Responses: make(map[string]*models.ReportResponse)}
+	var accepted []models.ReportRequest
+	for _, req := range batch.Reports {
+		if req.Hostname == "" {
+			continue
+		}
+		accepted = append(accepted, req)
+		if resp := c.takeRelayResponse(req.NodeKey()); resp != nil {
+			result.Responses[req.NodeKey()] = resp
+		}
+	}
+	c.bufferRelayReports(accepted)
+	result.Accepted = len(accepted)
+
+	sendRelayResponse(w, http.StatusOK, true, "批量上报成功", result)
+}
+
+// bufferRelayReports 缓存待转发的上报（去掉本地密码），超出上限时丢弃最旧的
+func (c *Client) bufferRelayReports(reports []models.ReportRequest) {
+	c.relayMutex.Lock()
+	defer c.relayMutex.Unlock()
+
+	for _, req := range reports {
+		req.Password = ""
+		c.relayBuffer = append(c.relayBuffer, req)
+	}
+	c.trimRelayBuffer()
+}
+
+// trimRelayBuffer 须持有 relayMutex
+func (c *Client) trimRelayBuffer() {
+	if dropped := len(c.relayBuffer) - maxRelayBuffered; dropped > 0 {
+		c.relayBuffer = c.relayBuffer[dropped:]
+		c.relayDropped += dropped
+		reportLog.Warn("中继缓存已满，丢弃最旧的上报", "dropped", dropped)
+	}
+}
+
+// takeRelayResponse 取出并清除服务端给该节点的待转交响应
+func (c *Client) takeRelayResponse(key string) *models.ReportResponse {
+	c.relayMutex.Lock()
+	defer c.relayMutex.Unlock()
+
+	resp := c.relayResponses[key]
+	delete(c.relayResponses, key)
+	return resp
+}
+
+// forwardRelayReports 将缓存的本地上报与本机上报合并为一批发送；失败时缓存的上报放回队列等待重发
+func (c *Client) forwardRelayReports(request models.ReportRequest, serverURL, password, compression string) error {
+	c.relayMutex.Lock()
+	relayed := c.relayBuffer
+	c.relayBuffer = nil
+	dropped := c.relayDropped
+	c.relayMutex.Unlock()
+
+	hosts := make(map[string]bool)
+	for i := range relayed {
+		relayed[i].Via = request.Hostname
+		hosts[relayed[i].NodeKey()] = true
+	}
+
+	// 中继自身的健康状况随本机上报发送
+	request.Relay = &models.RelayStats{
+		Clients:   len(hosts),
+		Forwarded: len(relayed),
+		Dropped:   dropped,
+	}
+
+	batch := models.BatchReportRequest{
+		Password: password,
+		Reports:  append(append([]models.ReportRequest(nil), relayed...), request),
+	}
+
+	var batchResp models.BatchReportResponse
+	url := fmt.Sprintf("%s/api/report/batch", serverURL)
+	if err := c.postJSON(url, batch, compression, &batchResp); err != nil {
+		c.relayMutex.Lock()
+		c.relayBuffer = append(relayed, c.relayBuffer...)
+		c.trimRelayBuffer()
+		c.relayMutex.Unlock()
+		return fmt.Errorf("中继转发失败（%d 条待重发）: %v", len(relayed), err)
+	}
+
+	reportLog.Info("中继转发完成", "nodes", len(hosts), "reports", len(relayed),
+		"accepted", batchResp.Accepted, "duplicates", batchResp.Duplicates)
+
+	// 保存服务端给本地节点的指令，待其下次上报时转交；一次性指令取并集
+	c.relayMutex.Lock()
+	for key, resp := range batchResp.Responses {
+		if key == request.NodeKey() {
+			continue
+		}
+		if pending, ok := c.relayResponses[key]; ok {
+			resp.SpeedTestRequested = resp.SpeedTestRequested || pending.SpeedTestRequested
+			if resp.Config == nil {
+				resp.Config = pending.Config
+			}
+		}
+		c.relayResponses[key] = resp
+	}
+	c.relayMutex.Unlock()
+
+	if resp, ok := batchResp.Responses[request.NodeKey()]; ok {
+		c.handleReportResponse(request, resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"fmt"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func (c *Client) getConfigVersion() string {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.ConfigVersion
+}
+
+// applyPushedConfig 应用服务端下发的配置：先与本地配置合并并校验，通过后保存到单独的下发配置文件并替换当前配置，
+// 下次上报时携带新版本号作为确认；校验失败时不保存，也不确认该版本。本地配置文件始终不被修改
+func (c *Client) applyPushedConfig(pushed *models.PushedConfig) error {
+	// 抓取模式下确认版本要等到下一次采集，期间服务端可能重复下发同一版本
+	if pushed.Version == c.rejectedConfigVersion || pushed.Version == c.getConfigVersion() {
+		return nil
+	}
+
+	// 新版本替换而不是叠加旧的下发配置，旧版本设置而新版本未设置的字段恢复为本地值
+	config, err := models.LoadLocalClientConfig(c.configPath)
+	if err != nil {
+		return fmt.Errorf("读取本地配置失败: %v", err)
+	}
+
+	pushed.Config.Apply(config)
+	config.ConfigVersion = pushed.Version
+	if err := config.Validate(); err != nil {
+		c.rejectedConfigVersion = pushed.Version
+		return fmt.Errorf("下发配置 %s 无效，继续使用原配置:\n%v", pushed.Version, err)
+	}
+
+	if err := models.SavePushedConfig(models.PushedConfigPath(c.configPath), pushed); err != nil {
+		return fmt.Errorf("保存下发配置失败: %v", err)
+	}
+	c.rejectedConfigVersion = ""
+	c.applyConfig(config)
+
+	configLog.Info("已应用服务端下发配置", "version", pushed.Version)
+	return nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// newPushTestClient 在临时目录写入本地配置并创建客户端，返回客户端和配置文件路径
+func newPushTestClient(t *testing.T, serverURL string) (*Client, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "client.json")
+	local := &models.ClientConfig{
+		Password:              "pw",
+		ServerURL:             serverURL,
+		ScrapeListen:          "127.0.0.1:9101", // 只用于通过校验，测试不启动抓取接口
+		Hostname:              "web-1",
+		ReportIntervalSeconds: 60,
+		InterfaceName:         "eth0",
+	}
+	if err := models.SaveClientConfig(path, local); err != nil {
+		t.Fatal(err)
+	}
+	config, err := models.LoadClientConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(config, path), path
+}
+
+func TestApplyPushedConfig(t *testing.T) {
+	c, path := newPushTestClient(t, "http://127.0.0.1:1")
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interval := 30
+	iface := "eth1"
+	invalid := &models.ClientThresholdConfig{Dynamic: []models.TimeWindowThreshold{{Start: "25:00", End: "09:00", BandwidthMbps: 100}}}
+	tests := []struct {
+		name      string
+		pushed    models.PushedConfig
+		wantErr   bool
+		interval  int
+		iface     string
+		version   string // 应用后确认的版本
+		persisted string // 下发配置文件中的版本
+	}{
+		{"应用下发配置", models.PushedConfig{Version: "v1", Config: models.ManagedClientConfig{ReportIntervalSeconds: &interval}}, false, 30, "eth0", "v1", "v1"},
+		{"重复下发同一版本", models.PushedConfig{Version: "v1", Config: models.ManagedClientConfig{ReportIntervalSeconds: &interval}}, false, 30, "eth0", "v1", "v1"},
+		{"校验失败不应用", models.PushedConfig{Version: "v2", Config: models.ManagedClientConfig{Threshold: invalid}}, true, 30, "eth0", "v1", "v1"},
+		{"已拒绝的版本不再重试", models.PushedConfig{Version: "v2", Config: models.ManagedClientConfig{Threshold: invalid}}, false, 30, "eth0", "v1", "v1"},
+		{"新版本替换旧的下发配置", models.PushedConfig{Version: "v3", Config: models.ManagedClientConfig{InterfaceName: &iface}}, false, 60, "eth1", "v3", "v3"},
+	}
+	for _, tt := range tests {
+		pushed := tt.pushed
+		err := c.applyPushedConfig(&pushed)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err = %v", tt.name, err)
+		}
+		if got := c.getReportInterval(); got != tt.interval {
+			t.Errorf("%s: 上报间隔 = %d，期望 %d", tt.name, got, tt.interval)
+		}
+		if got := c.getInterfaceName(); got != tt.iface {
+			t.Errorf("%s: 网卡 = %q，期望 %q", tt.name, got, tt.iface)
+		}
+		if got := c.getConfigVersion(); got != tt.version {
+			t.Errorf("%s: 确认版本 = %q，期望 %q", tt.name, got, tt.version)
+		}
+
+		saved, err := models.LoadPushedConfig(models.PushedConfigPath(path))
+		if err != nil || saved == nil || saved.Version != tt.persisted {
+			t.Errorf("%s: 下发配置文件 = %+v（%v），期望版本 %s", tt.name, saved, err, tt.persisted)
+		}
+		if data, _ := os.ReadFile(path); !bytes.Equal(data, original) {
+			t.Fatalf("%s: 本地配置文件被修改:\n%s", tt.name, data)
+		}
+	}
+
+	// 重启后本地配置叠加下发配置，版本保持已确认的值
+	reloaded, err := models.LoadClientConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.ConfigVersion != "v3" || reloaded.InterfaceName != "eth1" || reloaded.ReportIntervalSeconds != 60 {
+		t.Errorf("重新加载的配置 version=%q interface=%q interval=%d", reloaded.ConfigVersion, reloaded.InterfaceName, reloaded.ReportIntervalSeconds)
+	}
+	local, err := models.LoadLocalClientConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.ConfigVersion != "" || local.InterfaceName != "eth0" {
+		t.Errorf("本地配置 version=%q interface=%q，不应包含下发内容", local.ConfigVersion, local.InterfaceName)
+	}
+}
+
+func TestPushedConfigAcknowledged(t *testing.T) {
+	// 仅抓取模式下上报只保存在本地，便于检查上报中携带的版本
+	c, path := newPushTestClient(t, "")
+	c.config.InterfaceName = ""
+
+	reportedVersion := func(c *Client) string {
+		t.Helper()
+		if err := c.reportMetrics(); err != nil {
+			t.Fatal(err)
+		}
+		c.latestMutex.RLock()
+		defer c.latestMutex.RUnlock()
+		return c.latestReport.ConfigVersion
+	}
+
+	if got := reportedVersion(c); got != "" {
+		t.Fatalf("未下发配置时上报版本 = %q", got)
+	}
+
+	interval := 30
+	if err := c.applyPushedConfig(&models.PushedConfig{Version: "v1", Config: models.ManagedClientConfig{ReportIntervalSeconds: &interval}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := reportedVersion(c); got != "v1" {
+		t.Errorf("应用后上报版本 = %q，期望 v1", got)
+	}
+
+	invalid := &models.ClientThresholdConfig{Dynamic: []models.TimeWindowThreshold{{Start: "9", End: "18:00"}}}
+	if err := c.applyPushedConfig(&models.PushedConfig{Version: "v2", Config: models.ManagedClientConfig{Threshold: invalid}}); err == nil {
+		t.Fatal("无效的下发配置应被拒绝")
+	}
+	if got := reportedVersion(c); got != "v1" {
+		t.Errorf("拒绝后上报版本 = %q，应仍确认 v1", got)
+	}
+
+	// 重启后继续确认已应用的版本，服务端不会重复下发
+	config, err := models.LoadClientConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.InterfaceName = ""
+	if got := reportedVersion(NewClient(config, path)); got != "v1" {
+		t.Errorf("重启后上报版本 = %q，期望 v1", got)
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// subSample 一次子采样结果
+type subSample struct {
+	inBps      float64
+	outBps     float64
+	cpuPercent float64
+	hasCPU     bool
+}
+
+func (c *Client) getSamplingConfig() models.SamplingConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.Sampling
+}
+
+// sampler 在两次上报之间按子采样间隔采集网络（及可选的CPU）数据
+func (c *Client) sampler() {
+	defer c.wg.Done()
+
+	for {
+		wait := 5 * time.Second // 未启用时定期检查配置
+		if interval := c.getSamplingConfig().IntervalSeconds; interval > 0 {
+			wait = time.Duration(interval) * time.Second
+		}
+
+		select {
+		case <-time.After(wait):
+			cfg := c.getSamplingConfig()
+			if cfg.IntervalSeconds <= 0 {
+				// 禁用时丢弃基线，重新启用后从头计算
+				c.samplerMutex.Lock()
+				c.samples = nil
+				c.samplerMutex.Unlock()
+				c.sampleTracker.reset()
+				continue
+			}
+			c.takeSubSample(cfg.IncludeCPU)
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// takeSubSample 采集一次子样本
+func (c *Client) takeSubSample(includeCPU bool) {
+	stats, err := c.selectInterfaceCounters()
+	if err != nil {
+		collectorLog.Warn("子采样获取网络计数失败", "error", err)
+		return
+	}
+
+	inBps, outBps, _, valid := c.sampleTracker.update(stats, time.Now())
+	if !valid {
+		// 首次采样或所有网卡都在重建基线
+		return
+	}
+
+	sample := subSample{inBps: inBps, outBps: outBps}
+	if includeCPU {
+		// 间隔为0时返回自上次调用以来的CPU使用率
+		if percent, err := cpu.Percent(0, false); err == nil && len(percent) > 0 {
+			sample.cpuPercent = percent[0]
+			sample.hasCPU = true
+		}
+	}
+
+	c.samplerMutex.Lock()
+	c.samples = append(c.samples, sample)
+	c.samplerMutex.Unlock()
+}
+
+// takeIntervalStats 汇总并清空本上报区间的子样本
+func (c *Client) takeIntervalStats() *models.IntervalStats {
+	c.samplerMutex.Lock()
+	samples := c.samples
+	c.samples = nil
+	c.samplerMutex.Unlock()
+
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var in, out, cpuValues []float64
+	for _, s := range samples {
+		in = append(in, s.inBps)
+		out = append(out, s.outBps)
+		if s.hasCPU {
+			cpuValues = append(cpuValues, s.cpuPercent)
+		}
+	}
+
+	stats := &models.IntervalStats{
+		Samples:       len(samples),
+		NetworkInBps:  aggregate(in),
+		NetworkOutBps: aggregate(out),
+	}
+	if len(cpuValues) > 0 {
+		cpuStats := aggregate(cpuValues)
+		stats.CPUPercent = &cpuStats
+	}
+
+	return stats
+}
+
+// aggregate 计算最小值、平均值、最大值和P95（最近秩法）
+func aggregate(values []float64) models.AggregateStats {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return models.AggregateStats{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P95: sorted[rank],
+	}
+}
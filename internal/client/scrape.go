@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func (c *Client) setLatestReport(request models.ReportRequest) {
+	request.Password = ""
+
+	c.latestMutex.Lock()
+	c.latestReport = &request
+	c.latestMutex.Unlock()
+}
+
+// startScrapeServer 启动本地抓取接口，供服务端在无法主动上报时拉取最新指标
+func (c *Client) startScrapeServer() error {
+	c.configMutex.RLock()
+	listen := c.config.ScrapeListen
+	c.configMutex.RUnlock()
+
+	if listen == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/metrics", c.handleScrape)
+	c.scrapeServer = &http.Server{Addr: listen, Handler: mux}
+
+	go func() {
+		reportLog.Info("抓取接口已启动", "listen", listen)
+		if err := c.scrapeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			reportLog.Error("抓取接口退出", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// handleScrape GET 返回最近一次采集的指标与有效阈值，POST 接收服务端回传的响应（请求头 X-Password 需与客户端密码一致）
+func (c *Client) handleScrape(w http.ResponseWriter, r *http.Request) {
+	c.configMutex.RLock()
+	password := c.config.Password
+	c.configMutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(models.APIResponse{Success: false, Message: "仅支持GET和POST方法"})
+		return
+	}
+	if !passwordMatches(r.Header.Get("X-Password"), password) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.APIResponse{Success: false, Message: "密码错误"})
+		return
+	}
+	if r.Method == http.MethodPost {
+		c.handleScrapeAck(w, r)
+		return
+	}
+
+	c.latestMutex.RLock()
+	latest := c.latestReport
+	c.latestMutex.RUnlock()
+
+	if latest == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(models.APIResponse{Success: false, Message: "尚未完成首次采集"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.APIResponse{Success: true, Message: "获取指标成功", Data: latest})
+}
+
+// handleScrapeAck 接收服务端处理抓取结果后的响应，交由上报循环执行其中的指令
+func (c *Client) handleScrapeAck(w http.ResponseWriter, r *http.Request) {
+	var ack models.ScrapeAck
+	if err := json.NewDecoder(r.Body).Decode(&ack); err != nil || ack.Response == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.APIResponse{Success: false, Message: "无效的请求数据"})
+		return
+	}
+
+	// 进程重启前的上报对应的响应不再适用
+	if ack.Session != c.session {
+		json.NewEncoder(w).Encode(models.APIResponse{Success: true, Message: "会话已变化，忽略响应"})
+		return
+	}
+
+	if ack.SpeedTestTimestamp > 0 {
+		c.ackSpeedTestResult(ack.SpeedTestTimestamp)
+	}
+
+	// 合并尚未处理的响应：一次性指令取并集，其余取最新
+	resp := ack.Response
+	c.latestMutex.Lock()
+	if pending := c.scrapeResponse; pending != nil {
+		resp.SpeedTestRequested = resp.SpeedTestRequested || pending.SpeedTestRequested
+		if resp.Config == nil {
+			resp.Config = pending.Config
+		}
+	}
+	c.scrapeResponse = resp
+	c.latestMutex.Unlock()
+
+	json.NewEncoder(w).Encode(models.APIResponse{Success: true, Message: "响应已接收"})
+}
+
+// takeScrapeResponse 取出待处理的服务端回传响应
+func (c *Client) takeScrapeResponse() *models.ReportResponse {
+	c.latestMutex.Lock()
+	defer c.latestMutex.Unlock()
+	resp := c.scrapeResponse
+	c.scrapeResponse = nil
+	return resp
+}
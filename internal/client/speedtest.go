@@ -0,0 +1,214 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// timedReader 在截止时间前持续产生数据，用于上传测速
+type timedReader struct {
+	deadline time.Time
+	read     int64
+}
+
+func (r *timedReader) Read(p []byte) (int, error) {
+	if time.Now().After(r.deadline) {
+		return 0, io.EOF
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func (c *Client) getSpeedTestConfig() models.SpeedTestConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.SpeedTest
+}
+
+// checkSpeedTestSchedule 检查是否到达定时测速时间
+func (c *Client) checkSpeedTestSchedule() {
+	cfg := c.getSpeedTestConfig()
+	if !cfg.Enabled || cfg.IntervalMinutes <= 0 {
+		return
+	}
+
+	c.speedTestMutex.Lock()
+	due := time.Since(c.lastSpeedTestAt) >= time.Duration(cfg.IntervalMinutes)*time.Minute
+	c.speedTestMutex.Unlock()
+
+	if due {
+		c.startSpeedTest()
+	}
+}
+
+// startSpeedTest 在后台执行一次测速，结果随下一次上报发送
+func (c *Client) startSpeedTest() {
+	cfg := c.getSpeedTestConfig()
+	if !cfg.Enabled {
+		reportLog.Info("测速未启用，忽略测速请求")
+		return
+	}
+
+	c.configMutex.RLock()
+	serverURL := c.config.ServerURL
+	c.configMutex.RUnlock()
+	if serverURL == "" {
+		// 测速需要向服务端HTTP接口收发数据，仅抓取的节点无法主动连接服务端
+		reportLog.Warn("未配置 server_url，无法测速")
+		return
+	}
+
+	c.speedTestMutex.Lock()
+	if c.speedTestRunning {
+		c.speedTestMutex.Unlock()
+		return
+	}
+	c.speedTestRunning = true
+	c.lastSpeedTestAt = time.Now()
+	c.speedTestMutex.Unlock()
+
+	go func() {
+		result := c.runSpeedTest(cfg.DurationSeconds)
+
+		c.speedTestMutex.Lock()
+		c.speedTestResult = result
+		c.speedTestRunning = false
+		c.speedTestMutex.Unlock()
+
+		if result.Error != "" {
+			reportLog.Warn("测速失败", "error", result.Error)
+		} else {
+			reportLog.Info("测速完成", "download_mbps", result.DownloadMbps, "upload_mbps", result.UploadMbps)
+		}
+	}()
+}
+
+// takeSpeedTestResult 取出待上报的测速结果
+func (c *Client) takeSpeedTestResult() *models.SpeedTestResult {
+	c.speedTestMutex.Lock()
+	defer c.speedTestMutex.Unlock()
+	result := c.speedTestResult
+	c.speedTestResult = nil
+	return result
+}
+
+// restoreSpeedTestResult 上报失败时放回测速结果（不覆盖更新的结果）
+func (c *Client) restoreSpeedTestResult(result *models.SpeedTestResult) {
+	if result == nil {
+		return
+	}
+	c.speedTestMutex.Lock()
+	defer c.speedTestMutex.Unlock()
+	if c.speedTestResult == nil && result.Timestamp != c.speedTestAcked {
+		c.speedTestResult = result
+	}
+}
+
+// ackSpeedTestResult 服务端确认已记录某次测速结果后不再随抓取结果附带
+func (c *Client) ackSpeedTestResult(timestamp int64) {
+	c.speedTestMutex.Lock()
+	defer c.speedTestMutex.Unlock()
+	c.speedTestAcked = timestamp
+	if c.speedTestResult != nil && c.speedTestResult.Timestamp == timestamp {
+		c.speedTestResult = nil
+	}
+}
+
+// runSpeedTest 依次执行下载与上传测速
+func (c *Client) runSpeedTest(seconds int) *models.SpeedTestResult {
+	c.configMutex.RLock()
+	password := c.config.Password
+	serverURL := c.config.ServerURL
+	c.configMutex.RUnlock()
+
+	result := &models.SpeedTestResult{Timestamp: c.now().Unix()}
+
+	// 测速请求需要比测速时长更长的超时
+	httpClient := &http.Client{Timeout: time.Duration(seconds)*time.Second + 10*time.Second}
+
+	down, err := speedTestDownload(httpClient, serverURL, password, seconds)
+	if err != nil {
+		result.Error = fmt.Sprintf("下载测速失败: %v", err)
+		return result
+	}
+	result.DownloadMbps = down
+
+	up, err := speedTestUpload(httpClient, serverURL, password, seconds)
+	if err != nil {
+		result.Error = fmt.Sprintf("上传测速失败: %v", err)
+		return result
+	}
+	result.UploadMbps = up
+
+	return result
+}
+
+func speedTestDownload(httpClient *http.Client, serverURL, password string, seconds int) (float64, error) {
+	url := fmt.Sprintf("%s/api/speedtest/download?seconds=%d", serverURL, seconds)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Password", password)
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("服务器返回状态码 %d", resp.StatusCode)
+	}
+
+	received, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return bytesToMbps(received, time.Since(start)), nil
+}
+
+func speedTestUpload(httpClient *http.Client, serverURL, password string, seconds int) (float64, error) {
+	url := fmt.Sprintf("%s/api/speedtest/upload?seconds=%d", serverURL, seconds)
+	body := &timedReader{deadline: time.Now().Add(time.Duration(seconds) * time.Second)}
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Password", password)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var response models.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("响应解析失败: %v", err)
+	}
+	if !response.Success {
+		return 0, fmt.Errorf("服务器返回错误: %s", response.Message)
+	}
+
+	return bytesToMbps(body.read, time.Since(start)), nil
+}
+
+func bytesToMbps(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) * 8 / elapsed.Seconds() / 1e6
+}
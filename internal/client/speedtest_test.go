@@ -0,0 +1,34 @@
+package client
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/server"
+)
+
+func TestSpeedTestRoundTrip(t *testing.T) {
+	srv, err := server.NewServer(&models.ServerConfig{Password: "pw"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	c := NewClient(&models.ClientConfig{Password: "pw", ServerURL: ts.URL}, "")
+	result := c.runSpeedTest(1)
+	if result.Error != "" {
+		t.Fatalf("测速失败: %s", result.Error)
+	}
+	if result.DownloadMbps <= 0 || result.UploadMbps <= 0 {
+		t.Errorf("下载 %.1f Mbps、上传 %.1f Mbps，本机回环应均大于0", result.DownloadMbps, result.UploadMbps)
+	}
+
+	c = NewClient(&models.ClientConfig{Password: "wrong", ServerURL: ts.URL}, "")
+	result = c.runSpeedTest(1)
+	if !strings.Contains(result.Error, "下载测速失败") {
+		t.Errorf("密码错误时应下载失败，实际为 %q", result.Error)
+	}
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// nsCounter 网络命名空间的累计收发字节数
+type nsCounter struct {
+	recv uint64
+	sent uint64
+}
+
+// procTracker 跟踪进程CPU时间和网络命名空间计数，用于计算区间内的资源占用排行。
+// 只在上报循环中调用，不需要加锁。
+type procTracker struct {
+	lastCPU   map[int32]float64 // pid -> 累计CPU秒数
+	lastNS    map[string]nsCounter
+	lastAt    time.Time
+	hostNetNS string
+}
+
+func newProcTracker() *procTracker {
+	hostNS, _ := os.Readlink("/proc/self/ns/net")
+	return &procTracker{
+		lastCPU:   make(map[int32]float64),
+		lastNS:    make(map[string]nsCounter),
+		hostNetNS: hostNS,
+	}
+}
+
+func (c *Client) getTopProcessesConfig() models.TopProcessesConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config.TopProcesses
+}
+
+// shouldAttachTopProcesses 判断本次上报是否接近或超过阈值，需要附带进程快照
+func (c *Client) shouldAttachTopProcesses(cfg models.TopProcessesConfig, metrics *models.SystemMetrics, thresholdMbps float64) bool {
+	// 服务端在节点告警期间请求持续附带快照（标记在上报循环中读写，无需加锁）
+	if c.topProcessesRequested {
+		return true
+	}
+	if cfg.CPUPercent > 0 && metrics.CPUPercent >= cfg.CPUPercent {
+		return true
+	}
+	if cfg.MemoryPercent > 0 && metrics.MemoryTotal > 0 &&
+		float64(metrics.MemoryUsed)/float64(metrics.MemoryTotal)*100 >= cfg.MemoryPercent {
+		return true
+	}
+	if thresholdMbps > 0 {
+		inMbps := float64(metrics.NetworkInBps) / 125000.0
+		outMbps := float64(metrics.NetworkOutBps) / 125000.0
+		if inMbps < thresholdMbps || outMbps < thresholdMbps {
+			return true
+		}
+	}
+	return false
+}
+
+// collect 采集进程资源占用并返回各类排行前 n 名
+func (t *procTracker) collect(n int) (*models.TopProcesses, error) {
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, fmt.Errorf("获取进程列表失败: %v", err)
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(t.lastAt).Seconds()
+	firstRun := t.lastAt.IsZero()
+
+	currentCPU := make(map[int32]float64, len(pids))
+	currentNS := make(map[string]nsCounter)
+	var all []models.ProcessInfo
+	var byNetwork []models.ProcessInfo
+
+	for _, pid := range pids {
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			continue // 进程已退出
+		}
+
+		info := models.ProcessInfo{PID: pid}
+
+		if times, err := p.Times(); err == nil {
+			total := times.User + times.System
+			currentCPU[pid] = total
+			if last, ok := t.lastCPU[pid]; ok && !firstRun && elapsed > 0 && total >= last {
+				info.CPUPercent = (total - last) / elapsed * 100
+			}
+		}
+		if memInfo, err := p.MemoryInfo(); err == nil {
+			info.RSSBytes = memInfo.RSS
+		}
+		all = append(all, info)
+
+		// 独立网络命名空间（如容器）中的进程可从 /proc/<pid>/net/dev 得到该命名空间的网络流量
+		ns, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+		if err != nil || ns == t.hostNetNS {
+			continue
+		}
+		if _, seen := currentNS[ns]; seen {
+			continue // 每个命名空间只统计第一个（PID最小的）进程
+		}
+		counter, err := readNetDevTotals(fmt.Sprintf("/proc/%d/net/dev", pid))
+		if err != nil {
+			continue
+		}
+		currentNS[ns] = counter
+		if last, ok := t.lastNS[ns]; ok && !firstRun && elapsed > 0 &&
+			counter.recv >= last.recv && counter.sent >= last.sent {
+			netInfo := info
+			netInfo.NetInBps = uint64(float64(counter.recv-last.recv) / elapsed)
+			netInfo.NetOutBps = uint64(float64(counter.sent-last.sent) / elapsed)
+			byNetwork = append(byNetwork, netInfo)
+		}
+	}
+
+	t.lastCPU = currentCPU
+	t.lastNS = currentNS
+	t.lastAt = now
+
+	top := &models.TopProcesses{
+		ByCPU:    topN(all, n, func(a, b models.ProcessInfo) bool { return a.CPUPercent > b.CPUPercent }),
+		ByMemory: topN(all, n, func(a, b models.ProcessInfo) bool { return a.RSSBytes > b.RSSBytes }),
+		ByNetwork: topN(byNetwork, n, func(a, b models.ProcessInfo) bool {
+			return a.NetInBps+a.NetOutBps > b.NetInBps+b.NetOutBps
+		}),
+	}
+
+	// 只为入选的进程读取名称
+	for _, list := range [][]models.ProcessInfo{top.ByCPU, top.ByMemory, top.ByNetwork} {
+		for i := range list {
+			if p, err := process.NewProcess(list[i].PID); err == nil {
+				list[i].Name, _ = p.Name()
+			}
+		}
+	}
+
+	return top, nil
+}
+
+// topN 按 less 排序后返回前 n 个元素的副本
+func topN(list []models.ProcessInfo, n int, less func(a, b models.ProcessInfo) bool) []models.ProcessInfo {
+	sorted := append([]models.ProcessInfo(nil), list...)
+	sort.Slice(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// readNetDevTotals 汇总 /proc/net/dev 格式文件中非回环网卡的收发字节数
+func readNetDevTotals(path string) (nsCounter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nsCounter{}, err
+	}
+	defer f.Close()
+
+	var total nsCounter
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) == "lo" {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 9 {
+			continue
+		}
+		recv, err1 := strconv.ParseUint(fields[0], 10, 64)
+		sent, err2 := strconv.ParseUint(fields[8], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		total.recv += recv
+		total.sent += sent
+	}
+
+	return total, scanner.Err()
+}
@@ -0,0 +1,79 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxBodyBytes 解压后请求体的最大字节数
+const MaxBodyBytes = 32 << 20
+
+// EncodeJSON 将请求编码为JSON并按配置压缩（gzip/zstd），返回请求体和 Content-Encoding
+func EncodeJSON(v interface{}, compression string) (io.Reader, string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, "", fmt.Errorf("JSON编码失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	switch compression {
+	case "":
+		return bytes.NewReader(data), "", nil
+	case "gzip":
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, "", err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, "", err
+		}
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := zw.Write(data); err != nil {
+			return nil, "", err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", fmt.Errorf("不支持的压缩格式: %s", compression)
+	}
+
+	return &buf, compression, nil
+}
+
+// DecodeJSONBody 按 Content-Encoding 解压请求体（gzip/zstd）并解析JSON
+func DecodeJSONBody(r *http.Request, v interface{}) error {
+	var body io.Reader = r.Body
+
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("gzip解压失败: %v", err)
+		}
+		defer zr.Close()
+		body = zr
+	case "zstd":
+		zr, err := zstd.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("zstd解压失败: %v", err)
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		return fmt.Errorf("不支持的压缩格式: %s", r.Header.Get("Content-Encoding"))
+	}
+
+	return json.NewDecoder(io.LimitReader(body, MaxBodyBytes)).Decode(v)
+}
@@ -0,0 +1,142 @@
+// Package i18n 提供告警通知与接口提示的多语言文本
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lang 语言标识
+type Lang string
+
+// 支持的语言
+const (
+	ZhCN Lang = "zh-CN"
+	EN   Lang = "en"
+
+	// Default 未配置或无法识别时使用的语言
+	Default = ZhCN
+)
+
+// Supported 返回支持的语言列表
+func Supported() []Lang {
+	return []Lang{ZhCN, EN}
+}
+
+// ParseLang 识别语言标识，接受 zh、zh-CN、zh_CN、en、en-US 等写法
+func ParseLang(s string) (Lang, bool) {
+	s = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(s, "_", "-")))
+	switch {
+	case s == "zh" || strings.HasPrefix(s, "zh-"):
+		return ZhCN, true
+	case s == "en" || strings.HasPrefix(s, "en-"):
+		return EN, true
+	}
+	return "", false
+}
+
+// Resolve 返回第一个可识别的语言，均无法识别时返回 Default
+func Resolve(candidates ...string) Lang {
+	for _, candidate := range candidates {
+		if lang, ok := ParseLang(candidate); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// FromAcceptLanguage 按 Accept-Language 请求头的权重选择支持的语言，没有匹配时返回 fallback
+func FromAcceptLanguage(header string, fallback Lang) Lang {
+	type candidate struct {
+		lang    Lang
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, ok := ParseLang(tag)
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if v, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = v
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{lang, quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
+
+// T 返回指定语言的文本，有参数时按 fmt 格式化；缺少翻译时使用默认语言，仍缺少时返回 key
+func (l Lang) T(key string, args ...interface{}) string {
+	message, ok := catalogs[l][key]
+	if !ok {
+		if message, ok = catalogs[Default][key]; !ok {
+			message = key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Duration 将时长格式化为“1天2小时”或“1d 2h”这样的描述
+func (l Lang) Duration(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	switch {
+	case days > 0:
+		return l.T("duration.days_hours", days, hours)
+	case hours > 0:
+		return l.T("duration.hours_minutes", hours, minutes)
+	case minutes > 0:
+		return l.T("duration.minutes_seconds", minutes, seconds)
+	default:
+		return l.T("duration.seconds", seconds)
+	}
+}
+
+// Error 可按请求语言输出的错误，Error() 返回默认语言文本
+type Error struct {
+	Key  string
+	Args []interface{}
+}
+
+// NewError 创建可翻译的错误
+func NewError(key string, args ...interface{}) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return Default.T(e.Key, e.Args...)
+}
+
+// Localize 返回错误在指定语言下的文本，非 *Error 的错误原样输出
+func Localize(l Lang, err error) string {
+	var localized *Error
+	if errors.As(err, &localized) {
+		return l.T(localized.Key, localized.Args...)
+	}
+	return err.Error()
+}
@@ -0,0 +1,148 @@
+package i18n
+
+// catalogs 各语言的文本，key 按用途分组：tg.* 为Telegram通知，api.* 为接口提示
+var catalogs = map[Lang]map[string]string{
+	ZhCN: {
+		// 时长
+		"duration.days_hours":      "%d天%d小时",
+		"duration.hours_minutes":   "%d小时%d分钟",
+		"duration.minutes_seconds": "%d分%d秒",
+		"duration.seconds":         "%d秒",
+
+		// Telegram通知
+		"tg.bandwidth_alert":           "🚨 *带宽告警*\n\n节点: `%s`\n当前带宽: `%.2f Mbps`\n告警阈值: `%.2f Mbps`\n时间: `%s`",
+		"tg.bandwidth_recover":         "🟢 *带宽已恢复*\n\n节点: `%s`\n当前带宽: `%.2f Mbps`\n告警阈值: `%.2f Mbps`\n时间: `%s`",
+		"tg.offline_alert":             "❌ *节点离线告警*\n\n节点: `%s`\n离线时长: `%.0f分钟`\n时间: `%s`",
+		"tg.online_alert":              "✅ *节点重新上线*\n\n节点: `%s`\n时间: `%s`",
+		"tg.test_message":              "🤖 *带宽监控系统*\n\n测试消息发送成功！",
+		"tg.cpu_alert":                 "🔥 *CPU告警*\n\n节点: `%s`\n当前CPU: `%.2f%%`\n告警阈值: `%.2f%%`\n时间: `%s`",
+		"tg.cpu_recover":               "✅ *CPU已恢复*\n\n节点: `%s`\n当前CPU: `%.2f%%`\n告警阈值: `%.2f%%`\n时间: `%s`",
+		"tg.memory_alert":              "💾 *内存告警*\n\n节点: `%s`\n当前内存: `%.2f%%`\n告警阈值: `%.2f%%`\n时间: `%s`",
+		"tg.memory_recover":            "🟢 *内存已恢复*\n\n节点: `%s`\n当前内存: `%.2f%%`\n告警阈值: `%.2f%%`\n时间: `%s`",
+		"tg.speedtest_alert":           "🐢 *测速容量告警*\n\n节点: `%s`\n测速容量: `%.2f Mbps`\n告警阈值: `%.2f Mbps`\n时间: `%s`",
+		"tg.speedtest_recover":         "🟢 *测速容量已恢复*\n\n节点: `%s`\n测速容量: `%.2f Mbps`\n告警阈值: `%.2f Mbps`\n时间: `%s`",
+		"tg.clock_skew_alert":          "🕒 *时钟偏差告警*\n\n节点: `%s`\n时钟偏差: `%+d 秒`\n告警阈值: `±%d 秒`\n时间: `%s`",
+		"tg.clock_skew_recover":        "🟢 *时钟偏差已恢复*\n\n节点: `%s`\n时钟偏差: `%+d 秒`\n告警阈值: `±%d 秒`\n时间: `%s`",
+		"tg.hostname_conflict_alert":   "👯 *主机名冲突告警*\n\n主机名: `%s`\n节点标识: `%s`\n说明: 多台机器使用相同主机名上报，可能是克隆了相同的客户端配置\n时间: `%s`",
+		"tg.hostname_conflict_recover": "🟢 *主机名冲突已解除*\n\n主机名: `%s`\n时间: `%s`",
+		"tg.generic_alert":             "⚠️ *%s 告警*\n\n节点: `%s`\n当前值: `%.2f`\n告警阈值: `%.2f`\n时间: `%s`",
+		"tg.generic_recover":           "🟢 *%s 已恢复*\n\n节点: `%s`\n当前值: `%.2f`\n告警阈值: `%.2f`\n时间: `%s`",
+		"tg.rule_alert":                "%s *规则告警* `%s`\n\n级别: `%s`\n节点: `%s`\n条件: `%s`\n当前值: `%.2f`\n阈值: `%.2f`\n时间: `%s`",
+		"tg.rule_recover":              "🟢 *规则告警已恢复* `%s`\n\n节点: `%s`\n条件: `%s`\n当前值: `%.2f`\n时间: `%s`",
+		"tg.severity_info":             "提示",
+		"tg.severity_warning":          "警告",
+		"tg.severity_critical":         "严重",
+		"tg.node_pruned":               "🗑 *节点已自动删除*\n\n节点: `%s`\n离线时长: `%.1f天`\n时间: `%s`",
+		"tg.duration":                  "\n持续时长: `%s`",
+		"tg.top_cpu":                   "CPU占用最高的进程",
+		"tg.top_memory":                "内存占用最高的进程",
+		"tg.top_network":               "网络流量最高的命名空间进程",
+
+		// 接口提示
+		"api.post_only":                 "仅支持POST方法",
+		"api.get_only":                  "仅支持GET方法",
+		"api.method_not_allowed":        "不支持的方法",
+		"api.invalid_json":              "JSON解析失败",
+		"api.invalid_json_detail":       "JSON解析失败: %v",
+		"api.unauthorized":              "缺少有效的访问凭证",
+		"api.forbidden":                 "当前凭证无权访问该接口",
+		"api.invalid_password":          "密码错误",
+		"api.invalid_param":             "参数 %s 无效: %s",
+		"api.unsupported_sort":          "不支持的排序字段: %s",
+		"api.invalid_hostname_glob":     "主机名规则无效: %s",
+		"api.report_ok":                 "上报成功",
+		"api.batch_report_ok":           "批量上报成功",
+		"api.status_ok":                 "获取状态成功",
+		"api.summary_ok":                "获取汇总成功",
+		"api.telegram_not_configured":   "Telegram机器人未配置",
+		"api.test_message_failed":       "发送测试消息失败: %v",
+		"api.test_message_ok":           "测试消息发送成功",
+		"api.silences_ok":               "获取静默规则成功",
+		"api.invalid_silence_duration":  "静默时长无效",
+		"api.silence_created":           "静默规则已创建",
+		"api.silence_not_found":         "静默规则不存在",
+		"api.silence_deleted":           "静默规则已删除",
+		"api.incidents_ok":              "获取告警记录成功",
+		"api.missing_node":              "缺少节点参数",
+		"api.ambiguous_node":            "%s 对应多个节点，请使用节点标识",
+		"api.node_not_found":            "节点不存在",
+		"api.invalid_node_state":        "无效的节点状态: %s",
+		"api.node_updated":              "节点已更新",
+		"api.node_deleted":              "节点已删除",
+		"api.invalid_speedtest_seconds": "测速时长无效",
+		"api.speedtest_read_failed":     "读取测速数据失败: %v",
+		"api.speedtest_upload_ok":       "上传测速完成",
+		"api.speedtest_requested":       "测速请求已下发",
+		"api.streaming_unsupported":     "不支持流式响应",
+	},
+	EN: {
+		"duration.days_hours":      "%dd %dh",
+		"duration.hours_minutes":   "%dh %dm",
+		"duration.minutes_seconds": "%dm %ds",
+		"duration.seconds":         "%ds",
+
+		"tg.bandwidth_alert":           "🚨 *Bandwidth alert*\n\nNode: `%s`\nCurrent bandwidth: `%.2f Mbps`\nThreshold: `%.2f Mbps`\nTime: `%s`",
+		"tg.bandwidth_recover":         "🟢 *Bandwidth recovered*\n\nNode: `%s`\nCurrent bandwidth: `%.2f Mbps`\nThreshold: `%.2f Mbps`\nTime: `%s`",
+		"tg.offline_alert":             "❌ *Node offline*\n\nNode: `%s`\nOffline for: `%.0f min`\nTime: `%s`",
+		"tg.online_alert":              "✅ *Node back online*\n\nNode: `%s`\nTime: `%s`",
+		"tg.test_message":              "🤖 *Bandwidth Monitor*\n\nTest message sent successfully!",
+		"tg.cpu_alert":                 "🔥 *CPU alert*\n\nNode: `%s`\nCurrent CPU: `%.2f%%`\nThreshold: `%.2f%%`\nTime: `%s`",
+		"tg.cpu_recover":               "✅ *CPU recovered*\n\nNode: `%s`\nCurrent CPU: `%.2f%%`\nThreshold: `%.2f%%`\nTime: `%s`",
+		"tg.memory_alert":              "💾 *Memory alert*\n\nNode: `%s`\nCurrent memory: `%.2f%%`\nThreshold: `%.2f%%`\nTime: `%s`",
+		"tg.memory_recover":            "🟢 *Memory recovered*\n\nNode: `%s`\nCurrent memory: `%.2f%%`\nThreshold: `%.2f%%`\nTime: `%s`",
+		"tg.speedtest_alert":           "🐢 *Speed test capacity alert*\n\nNode: `%s`\nMeasured capacity: `%.2f Mbps`\nThreshold: `%.2f Mbps`\nTime: `%s`",
+		"tg.speedtest_recover":         "🟢 *Speed test capacity recovered*\n\nNode: `%s`\nMeasured capacity: `%.2f Mbps`\nThreshold: `%.2f Mbps`\nTime: `%s`",
+		"tg.clock_skew_alert":          "🕒 *Clock skew alert*\n\nNode: `%s`\nClock skew: `%+d s`\nThreshold: `±%d s`\nTime: `%s`",
+		"tg.clock_skew_recover":        "🟢 *Clock skew recovered*\n\nNode: `%s`\nClock skew: `%+d s`\nThreshold: `±%d s`\nTime: `%s`",
+		"tg.hostname_conflict_alert":   "👯 *Hostname conflict*\n\nHostname: `%s`\nNode IDs: `%s`\nNote: several machines report with the same hostname, possibly from a cloned client config\nTime: `%s`",
+		"tg.hostname_conflict_recover": "🟢 *Hostname conflict resolved*\n\nHostname: `%s`\nTime: `%s`",
+		"tg.generic_alert":             "⚠️ *%s alert*\n\nNode: `%s`\nCurrent value: `%.2f`\nThreshold: `%.2f`\nTime: `%s`",
+		"tg.generic_recover":           "🟢 *%s recovered*\n\nNode: `%s`\nCurrent value: `%.2f`\nThreshold: `%.2f`\nTime: `%s`",
+		"tg.rule_alert":                "%s *Rule alert* `%s`\n\nSeverity: `%s`\nNode: `%s`\nCondition: `%s`\nCurrent value: `%.2f`\nThreshold: `%.2f`\nTime: `%s`",
+		"tg.rule_recover":              "🟢 *Rule alert resolved* `%s`\n\nNode: `%s`\nCondition: `%s`\nCurrent value: `%.2f`\nTime: `%s`",
+		"tg.severity_info":             "info",
+		"tg.severity_warning":          "warning",
+		"tg.severity_critical":         "critical",
+		"tg.node_pruned":               "🗑 *Node removed automatically*\n\nNode: `%s`\nOffline for: `%.1f days`\nTime: `%s`",
+		"tg.duration":                  "\nDuration: `%s`",
+		"tg.top_cpu":                   "Top processes by CPU",
+		"tg.top_memory":                "Top processes by memory",
+		"tg.top_network":               "Top namespaced processes by network traffic",
+
+		"api.post_only":                 "Only POST is supported",
+		"api.get_only":                  "Only GET is supported",
+		"api.method_not_allowed":        "Method not allowed",
+		"api.invalid_json":              "Invalid JSON",
+		"api.invalid_json_detail":       "Invalid JSON: %v",
+		"api.unauthorized":              "Missing valid credentials",
+		"api.forbidden":                 "These credentials may not access this endpoint",
+		"api.invalid_password":          "Invalid password",
+		"api.invalid_param":             "Invalid parameter %s: %s",
+		"api.unsupported_sort":          "Unsupported sort field: %s",
+		"api.invalid_hostname_glob":     "Invalid hostname pattern: %s",
+		"api.report_ok":                 "Report accepted",
+		"api.batch_report_ok":           "Batch report accepted",
+		"api.status_ok":                 "Status retrieved",
+		"api.summary_ok":                "Summary retrieved",
+		"api.telegram_not_configured":   "Telegram bot is not configured",
+		"api.test_message_failed":       "Failed to send test message: %v",
+		"api.test_message_ok":           "Test message sent",
+		"api.silences_ok":               "Silences retrieved",
+		"api.invalid_silence_duration":  "Invalid silence duration",
+		"api.silence_created":           "Silence created",
+		"api.silence_not_found":         "Silence not found",
+		"api.silence_deleted":           "Silence deleted",
+		"api.incidents_ok":              "Incidents retrieved",
+		"api.missing_node":              "Missing node parameter",
+		"api.ambiguous_node":            "%s matches several nodes, use the node ID",
+		"api.node_not_found":            "Node not found",
+		"api.invalid_node_state":        "Invalid node state: %s",
+		"api.node_updated":              "Node updated",
+		"api.node_deleted":              "Node deleted",
+		"api.invalid_speedtest_seconds": "Invalid speed test duration",
+		"api.speedtest_read_failed":     "Failed to read speed test data: %v",
+		"api.speedtest_upload_ok":       "Upload speed test finished",
+		"api.speedtest_requested":       "Speed test requested",
+		"api.streaming_unsupported":     "Streaming is not supported",
+	},
+}
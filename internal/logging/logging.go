@@ -0,0 +1,127 @@
+// Package logging 提供分级的结构化日志，支持 text/JSON 输出、按模块区分和按大小轮转的日志文件
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"bandwidth-monitor/internal/models"
+)
+
+var (
+	level   = new(slog.LevelVar)
+	mutex   sync.RWMutex
+	current slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	output  io.Closer    // 日志文件，输出到标准错误时为nil
+	applied *models.LogConfig
+)
+
+// Setup 按配置设置日志级别、格式和输出，可重复调用（配置重载时）。
+// 标准库 log 的输出同样转入结构化日志，级别为 info。
+func Setup(config models.LogConfig) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	lvl, err := ParseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+	level.Set(lvl)
+
+	// 只改级别时不重建输出，避免重新打开日志文件
+	if applied != nil && config.Format == applied.Format && config.File == applied.File &&
+		config.MaxSizeMB == applied.MaxSizeMB && config.MaxBackups == applied.MaxBackups {
+		applied = &config
+		return nil
+	}
+
+	var w io.Writer = os.Stderr
+	var closer io.Closer
+	if config.File != "" {
+		file, err := openRotatingFile(config.File, config.MaxSizeMB, config.MaxBackups)
+		if err != nil {
+			return fmt.Errorf("打开日志文件失败: %v", err)
+		}
+		w, closer = file, file
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch config.Format {
+	case "", models.LogFormatText:
+		current = slog.NewTextHandler(w, opts)
+	case models.LogFormatJSON:
+		current = slog.NewJSONHandler(w, opts)
+	default:
+		if closer != nil {
+			closer.Close()
+		}
+		return fmt.Errorf("日志格式 %q 无效", config.Format)
+	}
+
+	if output != nil {
+		output.Close()
+	}
+	output = closer
+	if applied == nil {
+		slog.SetDefault(slog.New(dynamicHandler{}))
+	}
+	applied = &config
+	return nil
+}
+
+// ParseLevel 解析 debug、info、warn、error，留空为 info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("日志级别 %q 无效", name)
+}
+
+// Component 返回带 component 字段的日志记录器，Setup 之前创建的记录器在 Setup 后同样生效
+func Component(name string) *slog.Logger {
+	return slog.New(dynamicHandler{}).With("component", name)
+}
+
+// dynamicHandler 每次记录时转交给当前的处理器，使配置重载对已创建的记录器生效
+type dynamicHandler struct {
+	attrs []slog.Attr
+}
+
+func handler() slog.Handler {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return current
+}
+
+func (h dynamicHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h dynamicHandler) Handle(ctx context.Context, r slog.Record) error {
+	target := handler()
+	if len(h.attrs) > 0 {
+		target = target.WithAttrs(h.attrs)
+	}
+	return target.Handle(ctx, r)
+}
+
+func (h dynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return dynamicHandler{attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+// WithGroup 分组字段基于当前处理器创建，此后不再跟随配置重载
+func (h dynamicHandler) WithGroup(name string) slog.Handler {
+	return handler().WithAttrs(h.attrs).WithGroup(name)
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+
+	"bandwidth-monitor/internal/rules"
+)
+
+// AlertRule 表达式告警规则，如 `cpu > 90 && net_in_mbps < 10 for 5m`
+type AlertRule struct {
+	Name     string            `json:"name"`               // 告警类型，用于事件、告警记录、静默规则和通知模板
+	Expr     string            `json:"expr"`               // 规则表达式，可用变量见 rules.Variables，末尾可加 for 持续时间
+	Severity string            `json:"severity,omitempty"` // info、warning 或 critical
+	Labels   map[string]string `json:"labels,omitempty"`   // 随事件、告警记录和通知附带的标签
+	Message  AlertTemplate     `json:"message,omitempty"`  // 通知模板，优先于 alert_templates 中的同名模板
+}
+
+// 告警级别
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// 规则名称：小写字母开头，由小写字母、数字和下划线组成
+var alertRuleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// builtinAlertRules 由 thresholds 生成的内置告警规则（离线告警由超时检查单独处理）
+func builtinAlertRules(t Threshold) []AlertRule {
+	builtin := []AlertRule{
+		// 取上下行的较小值与节点阈值比较（瓶颈检测）
+		{Name: AlertBandwidth, Expr: "bandwidth_mbps < bandwidth_threshold_mbps", Severity: SeverityWarning},
+		{Name: AlertHostnameConflict, Expr: "hostname_nodes > 1", Severity: SeverityWarning},
+	}
+	if t.CPUPercent > 0 {
+		builtin = append(builtin, AlertRule{Name: AlertCPU, Expr: fmt.Sprintf("cpu > %g", t.CPUPercent), Severity: SeverityWarning})
+	}
+	if t.MemoryPercent > 0 {
+		builtin = append(builtin, AlertRule{Name: AlertMemory, Expr: fmt.Sprintf("mem > %g", t.MemoryPercent), Severity: SeverityWarning})
+	}
+	if t.SpeedTestMbps > 0 {
+		builtin = append(builtin, AlertRule{Name: AlertSpeedTest, Expr: fmt.Sprintf("speed_mbps < %g", t.SpeedTestMbps), Severity: SeverityWarning})
+	}
+	if t.ClockSkewSeconds > 0 {
+		// 客户端偏快为正、偏慢为负，按绝对值比较
+		expr := fmt.Sprintf("clock_skew > %d || clock_skew < -%d", t.ClockSkewSeconds, t.ClockSkewSeconds)
+		builtin = append(builtin, AlertRule{Name: AlertClockSkew, Expr: expr, Severity: SeverityWarning})
+	}
+	return builtin
+}
+
+// EffectiveAlertRules 生效的告警规则：内置规则在前，alert_rules 中的同名规则替换对应内置规则
+func (c *ServerConfig) EffectiveAlertRules() []AlertRule {
+	custom := make(map[string]bool)
+	for _, rule := range c.AlertRules {
+		custom[rule.Name] = true
+	}
+
+	var effective []AlertRule
+	for _, rule := range builtinAlertRules(c.Thresholds) {
+		if !custom[rule.Name] {
+			effective = append(effective, rule)
+		}
+	}
+	return append(effective, c.AlertRules...)
+}
+
+// EffectiveAlertTemplates 合并 alert_templates 和规则自带的通知模板
+func (c *ServerConfig) EffectiveAlertTemplates() map[string]AlertTemplate {
+	templates := make(map[string]AlertTemplate, len(c.AlertTemplates))
+	for alert, t := range c.AlertTemplates {
+		templates[alert] = t
+	}
+	for _, rule := range c.AlertRules {
+		t := templates[rule.Name]
+		if rule.Message.Firing != "" {
+			t.Firing = rule.Message.Firing
+		}
+		if rule.Message.Resolved != "" {
+			t.Resolved = rule.Message.Resolved
+		}
+		if t != (AlertTemplate{}) {
+			templates[rule.Name] = t
+		}
+	}
+	return templates
+}
+
+// checkAlertRules 检查规则名称、表达式、级别和通知模板
+func checkAlertRules(errs *ConfigErrors, alertRules []AlertRule) {
+	names := make(map[string]string)
+	for i, rule := range alertRules {
+		field := fmt.Sprintf("alert_rules[%d]", i)
+		switch {
+		case rule.Name == "":
+			errs.add(field+".name", "不能为空")
+		case !alertRuleNamePattern.MatchString(rule.Name):
+			errs.add(field+".name", "%q 无效，应由小写字母、数字和下划线组成且以字母开头", rule.Name)
+		case isAlertType(rule.Name) && !isBuiltinRule(rule.Name):
+			errs.add(field+".name", "%q 为内置告警，不能由规则定义", rule.Name)
+		default:
+			if other, ok := names[rule.Name]; ok {
+				errs.add(field+".name", "与 %s 重复", other)
+			}
+			names[rule.Name] = field
+		}
+
+		if rule.Expr == "" {
+			errs.add(field+".expr", "不能为空")
+		} else if _, err := rules.Parse(rule.Expr); err != nil {
+			errs.add(field+".expr", "%v", err)
+		}
+
+		switch rule.Severity {
+		case SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			errs.add(field+".severity", "%q 无效，应为 info、warning 或 critical", rule.Severity)
+		}
+		for key := range rule.Labels {
+			if key == "" {
+				errs.add(field+".labels", "标签名不能为空")
+			}
+		}
+
+		checkAlertTemplate(errs, field+".message", rule.Name, rule.Message)
+	}
+}
+
+// isBuiltinRule 是否为可被 alert_rules 替换的内置规则（除离线外的内置告警）
+func isBuiltinRule(name string) bool {
+	return isAlertType(name) && name != AlertOffline
+}
+
+// alertRuleNames 配置的规则名称，用于检查 alert_templates 的键
+func alertRuleNames(alertRules []AlertRule) []string {
+	names := make([]string, 0, len(alertRules))
+	for _, rule := range alertRules {
+		if rule.Name != "" {
+			names = append(names, rule.Name)
+		}
+	}
+	return names
+}
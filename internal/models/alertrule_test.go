@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"bandwidth-monitor/internal/rules"
+)
+
+func TestEffectiveAlertRules(t *testing.T) {
+	config := &ServerConfig{
+		Thresholds: Threshold{CPUPercent: 95, MemoryPercent: 90, SpeedTestMbps: 50, ClockSkewSeconds: 30},
+		AlertRules: []AlertRule{{Name: AlertCPU, Expr: "cpu > 80 for 5m", Severity: SeverityCritical}},
+	}
+
+	got := make(map[string]AlertRule)
+	for _, rule := range config.EffectiveAlertRules() {
+		if _, err := rules.Parse(rule.Expr); err != nil {
+			t.Errorf("规则 %s 解析失败: %v", rule.Name, err)
+		}
+		got[rule.Name] = rule
+	}
+
+	for _, name := range []string{AlertBandwidth, AlertCPU, AlertMemory, AlertSpeedTest, AlertClockSkew, AlertHostnameConflict} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("缺少内置规则 %s", name)
+		}
+	}
+	if _, ok := got[AlertOffline]; ok {
+		t.Error("离线告警不应由规则判断")
+	}
+	if got[AlertCPU].Expr != "cpu > 80 for 5m" {
+		t.Errorf("同名规则应替换内置规则，实际为 %q", got[AlertCPU].Expr)
+	}
+	if got[AlertClockSkew].Expr != "clock_skew > 30 || clock_skew < -30" {
+		t.Errorf("时钟偏差规则为 %q", got[AlertClockSkew].Expr)
+	}
+}
+
+func TestCheckAlertRulesReservedNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{AlertCPU, true},
+		{AlertClockSkew, true},
+		{AlertHostnameConflict, true},
+		{"busy_idle", true},
+		{AlertOffline, false},
+		{"Busy", false},
+	}
+	for _, tt := range tests {
+		var errs ConfigErrors
+		checkAlertRules(&errs, []AlertRule{{Name: tt.name, Expr: "cpu > 1", Severity: SeverityWarning}})
+		if valid := len(errs) == 0; valid != tt.valid {
+			t.Errorf("规则名 %q: valid = %v，期望 %v（%v）", tt.name, valid, tt.valid, errs)
+		}
+	}
+}
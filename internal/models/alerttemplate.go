@@ -0,0 +1,190 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 告警状态，用于选择通知模板
+const (
+	AlertStateFiring   = "firing"
+	AlertStateResolved = "resolved"
+)
+
+// AlertTypes 可配置通知模板的告警类型
+var AlertTypes = []string{
+	AlertBandwidth,
+	AlertCPU,
+	AlertMemory,
+	AlertSpeedTest,
+	AlertOffline,
+	AlertClockSkew,
+	AlertHostnameConflict,
+}
+
+// AlertTemplate 某类告警的通知模板（Go text/template 语法），留空的状态使用内置文本
+type AlertTemplate struct {
+	Firing   string `json:"firing,omitempty"`
+	Resolved string `json:"resolved,omitempty"`
+}
+
+// AlertMessage 一次告警通知的内容，也是通知模板可用的数据
+type AlertMessage struct {
+	Alert     string            // 告警类型，见 Alert* 常量，规则告警为规则名称
+	State     string            // firing 或 resolved
+	Severity  string            // 规则告警的级别：info、warning 或 critical
+	Labels    map[string]string // 规则告警的标签
+	Expr      string            // 规则告警的表达式
+	Node      NodeStatus        // 发送时的完整节点状态
+	Hostname  string            // 节点显示名称（显示名称优先于主机名）
+	Tags      map[string]string // 节点标签
+	Value     float64           // 当前值；离线告警为离线秒数，主机名冲突为冲突节点数，规则告警为首个数值比较左侧的值
+	Threshold float64           // 告警阈值；规则告警为首个数值比较右侧的值
+	NodeIDs   []string          // 主机名冲突涉及的节点标识
+	Details   string            // 进程排行等附加信息（已格式化为Markdown代码块）
+
+	Duration     time.Duration // 告警持续时长（恢复时）或离线时长（离线告警）
+	DurationText string        // 按通知语言格式化的 Duration
+	Time         time.Time     // 发送时间（通知时区）
+	DashboardURL string        // 由 dashboard_url 生成的节点链接，未配置时为空
+}
+
+// DashboardLink 按 dashboard_url 生成节点链接，未配置时返回空字符串
+func (c *ServerConfig) DashboardLink(nodeID, hostname string) string {
+	if c.DashboardURL == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{node_id}", url.QueryEscape(nodeID),
+		"{hostname}", url.QueryEscape(hostname),
+	).Replace(c.DashboardURL)
+}
+
+// alertTemplateFuncs 通知模板中可用的函数
+var alertTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// ParseAlertTemplate 解析通知模板
+func ParseAlertTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(alertTemplateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// AlertTemplateName 模板名，如 cpu.firing
+func AlertTemplateName(alert, state string) string {
+	return alert + "." + state
+}
+
+// sampleAlertMessage 用于加载时试运行模板的数据，指针字段均非空以便检查字段名
+func sampleAlertMessage(alert, state string) AlertMessage {
+	node := NodeStatus{
+		NodeID:    "sample",
+		Hostname:  "sample-host",
+		Tags:      map[string]string{},
+		LastSeen:  time.Now(),
+		IsOnline:  true,
+		SpeedTest: &SpeedTestResult{},
+		Relay:     &RelayStats{},
+	}
+	node.Metrics.TopProcesses = &TopProcesses{}
+
+	return AlertMessage{
+		Alert:        alert,
+		State:        state,
+		Severity:     SeverityWarning,
+		Labels:       map[string]string{},
+		Expr:         "cpu > 90",
+		Node:         node,
+		Hostname:     node.Hostname,
+		Tags:         node.Tags,
+		NodeIDs:      []string{"sample"},
+		Duration:     time.Minute,
+		DurationText: "1m",
+		Time:         time.Now(),
+		DashboardURL: "https://monitor.example.com/nodes/sample",
+	}
+}
+
+// checkAlertTemplates 解析并试运行每个通知模板，报告语法错误和不存在的字段；
+// 键可以是内置告警类型或 alert_rules 中的规则名称
+func checkAlertTemplates(errs *ConfigErrors, templates map[string]AlertTemplate, ruleNames []string) {
+	alerts := make([]string, 0, len(templates))
+	for alert := range templates {
+		alerts = append(alerts, alert)
+	}
+	sort.Strings(alerts)
+
+	for _, alert := range alerts {
+		field := "alert_templates." + alert
+		if !isAlertType(alert) && !containsString(ruleNames, alert) {
+			errs.add(field, "未知的告警类型，应为 %s 或 alert_rules 中的规则名称", strings.Join(AlertTypes, "、"))
+			continue
+		}
+		checkAlertTemplate(errs, field, alert, templates[alert])
+	}
+}
+
+// checkAlertTemplate 检查一类告警的 firing/resolved 模板
+func checkAlertTemplate(errs *ConfigErrors, field, alert string, t AlertTemplate) {
+	for _, state := range []struct {
+		name string
+		text string
+	}{
+		{AlertStateFiring, t.Firing},
+		{AlertStateResolved, t.Resolved},
+	} {
+		if state.text == "" {
+			continue
+		}
+		tmpl, err := ParseAlertTemplate(AlertTemplateName(alert, state.name), state.text)
+		if err != nil {
+			errs.add(field+"."+state.name, "模板语法错误: %v", err)
+			continue
+		}
+		if err := tmpl.Execute(io.Discard, sampleAlertMessage(alert, state.name)); err != nil {
+			errs.add(field+"."+state.name, "模板执行失败: %v", err)
+		}
+	}
+}
+
+func isAlertType(alert string) bool {
+	return containsString(AlertTypes, alert)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileAlertTemplates 解析全部通知模板，返回 模板名 -> 模板
+func CompileAlertTemplates(templates map[string]AlertTemplate) (map[string]*template.Template, error) {
+	compiled := make(map[string]*template.Template)
+	for alert, t := range templates {
+		for state, text := range map[string]string{AlertStateFiring: t.Firing, AlertStateResolved: t.Resolved} {
+			if text == "" {
+				continue
+			}
+			name := AlertTemplateName(alert, state)
+			tmpl, err := ParseAlertTemplate(name, text)
+			if err != nil {
+				return nil, fmt.Errorf("通知模板 %s 解析失败: %v", name, err)
+			}
+			compiled[name] = tmpl
+		}
+	}
+	return compiled, nil
+}
@@ -1,9 +1,16 @@
 package models
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -11,25 +18,154 @@ import (
 type ServerConfig struct {
 	Password   string    `json:"password"`
 	Listen     string    `json:"listen"`
+	GRPCListen string    `json:"grpc_listen,omitempty"` // gRPC监听地址（留空不启用）
+	GRPCTLS    TLSFiles  `json:"grpc_tls"`              // gRPC服务证书，非回环地址须设置证书或显式 plaintext
 	Domain     string    `json:"domain"`
 	Telegram   TGConfig  `json:"telegram"`
 	Thresholds Threshold `json:"thresholds"`
+
+	// 告警通知中节点链接的地址模板，{node_id}、{hostname} 替换为节点标识和主机名；留空不生成链接
+	DashboardURL string `json:"dashboard_url,omitempty"`
+
+	// 通知与接口提示的语言（zh-CN 或 en），及通知中时间的时区（IANA名称，留空使用服务器本地时区）
+	Language string `json:"language"`
+	Timezone string `json:"timezone,omitempty"`
+
+	// 告警通知模板，按告警类型配置 firing/resolved 两种状态，未配置的使用内置文本
+	AlertTemplates map[string]AlertTemplate `json:"alert_templates,omitempty"`
+
+	// 表达式告警规则，与 thresholds 生成的带宽、CPU、内存规则一起求值，同名时替换内置规则
+	AlertRules []AlertRule `json:"alert_rules,omitempty"`
+
+	// UDP心跳监听地址（留空不启用）
+	HeartbeatListen string `json:"heartbeat_listen,omitempty"`
+	// 节点首个心跳（或服务端重启后首个）的时间戳与服务端时间允许的偏差秒数，已扣除该节点测得的时钟偏差；
+	// 0 或未设置为60，负数表示不检查
+	HeartbeatMaxSkewSeconds int `json:"heartbeat_max_skew_seconds,omitempty"`
+
+	// API密钥及角色，password 只视为 reporter 角色，读取和管理接口须使用 viewer/admin 密钥
+	APIKeys []APIKey `json:"api_keys,omitempty"`
+
+	// 主动抓取的客户端列表（用于无法主动连接服务端的节点）
+	ScrapeTargets         []ScrapeTarget `json:"scrape_targets,omitempty"`
+	ScrapeIntervalSeconds int            `json:"scrape_interval_seconds"`
+	ScrapeFailureLimit    int            `json:"scrape_failure_limit"` // 连续失败达到该次数视为离线
+
+	// 离线超过该天数的节点自动删除（0表示不删除）
+	PruneOfflineDays int `json:"prune_offline_days,omitempty"`
+
+	// 日志输出
+	Log LogConfig `json:"log"`
+
+	// 告警记录文件（JSON Lines）及保留天数
+	IncidentFile          string `json:"incident_file"`
+	IncidentRetentionDays int    `json:"incident_retention_days"`
+
+	// 告警静默规则文件，重启后保留未过期的静默
+	SilenceFile string `json:"silence_file"`
+
+	// 集中下发的客户端配置，按顺序匹配，第一条匹配的生效
+	ClientConfigs []ClientConfigRule `json:"client_configs,omitempty"`
+}
+
+// APIKey 带角色的访问密钥
+type APIKey struct {
+	Name string `json:"name"` // 用于日志区分密钥用途
+	Key  string `json:"key"`
+	Role string `json:"role"` // reporter、viewer 或 admin
+}
+
+// 访问角色
+const (
+	RoleReporter = "reporter" // 上报指标、参与测速
+	RoleViewer   = "viewer"   // 读取状态、事件和静默规则
+	RoleAdmin    = "admin"    // 全部权限
+)
+
+// ScrapeTarget 抓取目标
+type ScrapeTarget struct {
+	URL      string `json:"url"`                // 客户端抓取接口地址，如 http://10.0.0.5:9101
+	Hostname string `json:"hostname,omitempty"` // 覆盖客户端上报的主机名
+	Password string `json:"password,omitempty"` // 客户端密码，留空时使用服务端密码
+}
+
+// ClientConfigRule 一组节点的期望客户端配置
+type ClientConfigRule struct {
+	Hosts  []string            `json:"hosts"` // 主机名glob，为空表示全部节点
+	Config ManagedClientConfig `json:"config"`
+}
+
+// ManagedClientConfig 可由服务端集中管理的客户端配置字段，未设置的字段保持客户端本地值
+type ManagedClientConfig struct {
+	ReportIntervalSeconds *int                   `json:"report_interval_seconds,omitempty"`
+	InterfaceName         *string                `json:"interface_name,omitempty"`
+	Threshold             *ClientThresholdConfig `json:"threshold,omitempty"`
+}
+
+// Version 根据配置内容计算版本号，内容不变则版本不变
+func (m ManagedClientConfig) Version() string {
+	data, _ := json.Marshal(m)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Apply 将集中配置合并到客户端配置
+func (m ManagedClientConfig) Apply(config *ClientConfig) {
+	if m.ReportIntervalSeconds != nil {
+		config.ReportIntervalSeconds = *m.ReportIntervalSeconds
+	}
+	if m.InterfaceName != nil {
+		config.InterfaceName = *m.InterfaceName
+	}
+	if m.Threshold != nil {
+		config.Threshold = *m.Threshold
+	}
+}
+
+// PushedConfig 上报响应中下发的客户端配置
+type PushedConfig struct {
+	Version string              `json:"version"`
+	Config  ManagedClientConfig `json:"config"`
 }
 
 // TGConfig Telegram配置
 type TGConfig struct {
 	BotToken string `json:"bot_token"`
 	ChatID   int64  `json:"chat_id"`
+
+	// 单独指定该聊天的通知语言和时区，留空与服务端一致
+	Language string `json:"language,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
 }
 
 // Threshold 监控阈值配置
 type Threshold struct {
-	BandwidthMbps    float64 `json:"bandwidth_mbps"`
-	OfflineSeconds   int     `json:"offline_seconds"`
-	CPUPercent       float64 `json:"cpu_percent"`        // CPU占用告警阈值
-	MemoryPercent    float64 `json:"memory_percent"`     // 内存占用告警阈值
+	BandwidthMbps  float64 `json:"bandwidth_mbps"`
+	OfflineSeconds int     `json:"offline_seconds"`
+	CPUPercent     float64 `json:"cpu_percent"`     // CPU占用告警阈值
+	MemoryPercent  float64 `json:"memory_percent"`  // 内存占用告警阈值
+	SpeedTestMbps  float64 `json:"speed_test_mbps"` // 测速容量告警阈值（0表示禁用）
+
+	// 告警判断使用的区间统计值：current/min/avg/max/p95（留空为current）
+	BandwidthAggregate string `json:"bandwidth_aggregate,omitempty"`
+	CPUAggregate       string `json:"cpu_aggregate,omitempty"`
+
+	// 心跳超时秒数，仅对发送过心跳的节点生效；0 或未设置使用默认值15，负数表示不按心跳判断离线
+	HeartbeatOfflineSeconds int `json:"heartbeat_offline_seconds"`
+
+	// 客户端时钟偏差告警阈值（秒），0 或未设置使用默认值30，负数表示不告警
+	ClockSkewSeconds int `json:"clock_skew_seconds"`
 }
 
+// 区间统计值选项
+const (
+	AggregateCurrent = "current"
+	AggregateMin     = "min"
+	AggregateAvg     = "avg"
+	AggregateMax     = "max"
+	AggregateP95     = "p95"
+)
+
 // TimeWindowThreshold 按时间窗口动态阈值
 type TimeWindowThreshold struct {
 	Start         string  `json:"start"` // HH:MM
@@ -48,9 +184,172 @@ type ClientConfig struct {
 	Password              string                `json:"password"`
 	ServerURL             string                `json:"server_url"`
 	Hostname              string                `json:"hostname"`
+	Tags                  map[string]string     `json:"tags,omitempty"` // 节点标签，如 {"role": "db"}，用于筛选
 	ReportIntervalSeconds int                   `json:"report_interval_seconds"`
 	InterfaceName         string                `json:"interface_name"`
 	Threshold             ClientThresholdConfig `json:"threshold"`
+	SpeedTest             SpeedTestConfig       `json:"speed_test"`
+	Sampling              SamplingConfig        `json:"sampling"`
+	InterfaceFilter       InterfaceFilterConfig `json:"interface_filter"`
+	TopProcesses          TopProcessesConfig    `json:"top_processes"`
+	ConfigVersion         string                `json:"config_version,omitempty"`          // 已应用的服务端下发配置版本，存在下发配置文件时以其为准
+	Transport             string                `json:"transport,omitempty"`               // 上报方式：http（默认）或 grpc
+	BatchSize             int                   `json:"batch_size,omitempty"`              // 每次HTTP发送的上报条数（默认1，不批量）
+	BatchMaxDelaySeconds  int                   `json:"batch_max_delay_seconds,omitempty"` // 批量模式下最早一条上报的最长等待时间，到时未满也发送
+	Compression           string                `json:"compression,omitempty"`             // HTTP请求体压缩：gzip、zstd，留空不压缩
+	ScrapeListen          string                `json:"scrape_listen,omitempty"`           // 抓取接口监听地址（留空不启用）
+	GRPCAddress           string                `json:"grpc_address,omitempty"`            // gRPC服务地址 host:port
+	GRPCTLS               GRPCTLSConfig         `json:"grpc_tls"`
+
+	// UDP心跳（地址留空不发送）
+	HeartbeatAddress         string `json:"heartbeat_address,omitempty"` // 服务端心跳地址 host:port
+	HeartbeatIntervalSeconds int    `json:"heartbeat_interval_seconds"`
+
+	// 按服务端返回的时间校正本地时钟偏差（影响动态阈值时段判断）
+	ClockCorrection bool `json:"clock_correction,omitempty"`
+
+	// 中继模式：接收本地客户端上报并随本机上报批量转发
+	Relay RelayConfig `json:"relay"`
+
+	// 日志输出
+	Log LogConfig `json:"log"`
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level      string `json:"level"`          // debug、info、warn、error
+	Format     string `json:"format"`         // text 或 json
+	File       string `json:"file,omitempty"` // 日志文件，留空输出到标准错误
+	MaxSizeMB  int    `json:"max_size_mb"`    // 日志文件达到该大小后轮转
+	MaxBackups int    `json:"max_backups"`    // 保留的轮转文件数
+}
+
+// 日志格式
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// applyLogDefaults 应用日志配置默认值
+func applyLogDefaults(config *LogConfig) bool {
+	applied := false
+	if config.Level == "" {
+		config.Level = "info"
+		applied = true
+	}
+	if config.Format == "" {
+		config.Format = LogFormatText
+		applied = true
+	}
+	if config.MaxSizeMB <= 0 {
+		config.MaxSizeMB = 100
+		applied = true
+	}
+	if config.MaxBackups <= 0 {
+		config.MaxBackups = 5
+		applied = true
+	}
+	return applied
+}
+
+// TLSFiles 服务端证书与私钥（PEM文件）
+type TLSFiles struct {
+	CertFile  string `json:"cert_file,omitempty"`
+	KeyFile   string `json:"key_file,omitempty"`
+	Plaintext bool   `json:"plaintext,omitempty"` // 在非回环地址上显式使用明文（仅用于可信内网，客户端须同时设置 plaintext）
+}
+
+// GRPCTLSConfig 客户端gRPC连接的TLS设置：非回环地址默认使用TLS，回环地址默认明文
+type GRPCTLSConfig struct {
+	CAFile     string `json:"ca_file,omitempty"`     // 校验服务端证书的CA（PEM），留空使用系统根证书
+	ServerName string `json:"server_name,omitempty"` // 校验证书时使用的主机名，留空取 grpc_address 的主机部分
+	Plaintext  bool   `json:"plaintext,omitempty"`   // 强制使用明文连接（仅用于可信内网）
+}
+
+// Enabled 连接 address 时是否使用TLS：设置了 CA 或主机名，或地址不是回环地址
+func (t GRPCTLSConfig) Enabled(address string) bool {
+	if t.Plaintext {
+		return false
+	}
+	if t.CAFile != "" || t.ServerName != "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return !isLoopbackHost(host)
+}
+
+// isLoopbackHost host 是否为回环地址（localhost 或回环IP），空主机名表示所有网卡，不属于回环地址
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RelayConfig 中继配置
+type RelayConfig struct {
+	Listen   string `json:"listen,omitempty"`   // 接收本地客户端上报的监听地址（留空不启用）
+	Password string `json:"password,omitempty"` // 本地客户端使用的密码，留空时与本机密码相同
+}
+
+// RelayStats 中继运行状态，随中继节点自身的上报发送
+type RelayStats struct {
+	Clients   int `json:"clients"`   // 本轮转发涉及的节点数
+	Forwarded int `json:"forwarded"` // 本轮转发的上报条数
+	Dropped   int `json:"dropped"`   // 启动以来因缓存已满丢弃的上报条数
+}
+
+// 上报方式
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// TopProcessesConfig 接近阈值时附带资源占用进程快照的配置
+type TopProcessesConfig struct {
+	Enabled       bool    `json:"enabled"`
+	Count         int     `json:"count"`          // 每类排行列出的进程数
+	CPUPercent    float64 `json:"cpu_percent"`    // CPU达到该值时附带快照
+	MemoryPercent float64 `json:"memory_percent"` // 内存达到该值时附带快照
+}
+
+// InterfaceFilterConfig 自动选择网卡时的过滤规则（指定 interface_name 时不生效）
+type InterfaceFilterConfig struct {
+	Include              []string `json:"include"`                // 为空表示全部网卡；glob 或 "re:" 前缀的正则
+	Exclude              []string `json:"exclude"`                // 匹配的网卡不参与统计
+	PhysicalOnly         bool     `json:"physical_only"`          // 仅统计物理设备（/sys/class/net/<iface>/device 存在）
+	ExcludeBridgeMembers bool     `json:"exclude_bridge_members"` // 跳过网桥成员（/sys/class/net/<iface>/brport 存在）
+	RequireUp            bool     `json:"require_up"`             // 仅统计 operstate 为 up 的网卡
+}
+
+// DefaultInterfaceExcludes 默认排除的回环与虚拟网卡
+var DefaultInterfaceExcludes = []string{
+	"lo", "veth*", "docker*", "br-*", "virbr*", "vmnet*", "zt*", "tailscale*", "wg*",
+}
+
+// SamplingConfig 上报区间内的子采样配置
+type SamplingConfig struct {
+	IntervalSeconds int  `json:"interval_seconds"` // 子采样间隔（0表示禁用）
+	IncludeCPU      bool `json:"include_cpu"`      // 是否同时采样CPU
+}
+
+// SpeedTestConfig 客户端测速配置
+type SpeedTestConfig struct {
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"interval_minutes"` // 定时测速间隔（0表示仅响应服务端请求）
+	DurationSeconds int  `json:"duration_seconds"` // 上传/下载各自的测速时长
+}
+
+// SpeedTestResult 测速结果
+type SpeedTestResult struct {
+	Timestamp    int64   `json:"timestamp"`
+	DownloadMbps float64 `json:"download_mbps"`
+	UploadMbps   float64 `json:"upload_mbps"`
+	Error        string  `json:"error,omitempty"`
 }
 
 // SystemMetrics 系统指标数据
@@ -61,28 +360,353 @@ type SystemMetrics struct {
 	NetworkInBps  uint64  `json:"network_in_bps"`
 	NetworkOutBps uint64  `json:"network_out_bps"`
 	UptimeSeconds uint64  `json:"uptime_seconds"`
+
+	Stats      *IntervalStats    `json:"stats,omitempty"`      // 上报区间内的子采样统计
+	Interfaces []InterfaceStatus `json:"interfaces,omitempty"` // 参与统计的各网卡状态
+
+	TopProcesses *TopProcesses `json:"top_processes,omitempty"` // 接近或超过阈值时的进程快照
+}
+
+// MemoryPercent 内存使用百分比
+func (m *SystemMetrics) MemoryPercent() float64 {
+	if m.MemoryTotal == 0 {
+		return 0
+	}
+	return float64(m.MemoryUsed) / float64(m.MemoryTotal) * 100
+}
+
+// ProcessInfo 单个进程的资源占用
+type ProcessInfo struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSBytes   uint64  `json:"rss_bytes"`
+	NetInBps   uint64  `json:"net_in_bps,omitempty"` // 仅独立网络命名空间（如容器）中的进程可用
+	NetOutBps  uint64  `json:"net_out_bps,omitempty"`
+}
+
+// TopProcesses 资源占用排行
+type TopProcesses struct {
+	ByCPU     []ProcessInfo `json:"by_cpu"`
+	ByMemory  []ProcessInfo `json:"by_memory"`
+	ByNetwork []ProcessInfo `json:"by_network,omitempty"`
+}
+
+// 网卡计数状态
+const (
+	InterfaceStatusOK    = "ok"    // 正常计算速率
+	InterfaceStatusNew   = "new"   // 新出现，仅记录基线
+	InterfaceStatusReset = "reset" // 计数器重置或回绕，本次丢弃
+	InterfaceStatusGone  = "gone"  // 已消失
+)
+
+// InterfaceStatus 单个网卡的速率与计数状态
+type InterfaceStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	InBps  uint64 `json:"in_bps"`
+	OutBps uint64 `json:"out_bps"`
+}
+
+// AggregateStats 一组采样值的统计
+type AggregateStats struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P95 float64 `json:"p95"`
+}
+
+// Get 按名称取统计值，名称无效时返回false
+func (a AggregateStats) Get(name string) (float64, bool) {
+	switch name {
+	case AggregateMin:
+		return a.Min, true
+	case AggregateAvg:
+		return a.Avg, true
+	case AggregateMax:
+		return a.Max, true
+	case AggregateP95:
+		return a.P95, true
+	}
+	return 0, false
+}
+
+// IntervalStats 上报区间内的子采样统计
+type IntervalStats struct {
+	Samples       int             `json:"samples"`
+	NetworkInBps  AggregateStats  `json:"network_in_bps"`
+	NetworkOutBps AggregateStats  `json:"network_out_bps"`
+	CPUPercent    *AggregateStats `json:"cpu_percent,omitempty"`
 }
 
 // ReportRequest 上报请求
 type ReportRequest struct {
-	Password               string        `json:"password"`
-	Hostname               string        `json:"hostname"`
-	Timestamp              int64         `json:"timestamp"`
-	Metrics                SystemMetrics `json:"metrics"`
-	EffectiveThresholdMbps float64       `json:"effective_threshold_mbps"`
+	Password               string            `json:"password"`
+	NodeID                 string            `json:"node_id,omitempty"` // 稳定的节点标识，旧版客户端不发送
+	Hostname               string            `json:"hostname"`
+	Tags                   map[string]string `json:"tags,omitempty"`
+	Timestamp              int64             `json:"timestamp"`
+	Metrics                SystemMetrics     `json:"metrics"`
+	EffectiveThresholdMbps float64           `json:"effective_threshold_mbps"`
+	SpeedTest              *SpeedTestResult  `json:"speed_test,omitempty"`
+	ConfigVersion          string            `json:"config_version,omitempty"` // 客户端已应用的下发配置版本
+	Via                    string            `json:"via,omitempty"`            // 转发该上报的中继主机名
+	Session                string            `json:"session,omitempty"`        // 客户端进程启动时生成的随机标识
+	Seq                    int64             `json:"seq,omitempty"`            // 同一 session 内递增的上报序号，服务端据此去重
+	Relay                  *RelayStats       `json:"relay,omitempty"`          // 上报节点作为中继时的运行状态
+}
+
+// NodeKey 服务端区分节点使用的键：优先节点标识，旧版客户端回退到主机名
+func (r *ReportRequest) NodeKey() string {
+	if r.NodeID != "" {
+		return r.NodeID
+	}
+	return r.Hostname
+}
+
+// Heartbeat UDP心跳包
+type Heartbeat struct {
+	NodeID    string `json:"node_id,omitempty"`
+	Hostname  string `json:"hostname"`
+	Timestamp int64  `json:"timestamp"` // Unix纳秒，服务端据此拒绝重放
+	Signature string `json:"signature"`
+}
+
+// HeartbeatSignature 以密码为密钥计算心跳签名（HMAC-SHA256）
+func HeartbeatSignature(password, nodeID, hostname string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(password))
+	fmt.Fprintf(mac, "%s\n%s\n%d", nodeID, hostname, timestamp)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BatchReportRequest 批量上报请求，每条上报带各自的时间戳
+type BatchReportRequest struct {
+	Password string          `json:"password"`
+	Reports  []ReportRequest `json:"reports"` // 条目中的 password 字段被忽略
+}
+
+// BatchReportResponse 批量上报响应数据
+type BatchReportResponse struct {
+	Accepted   int                        `json:"accepted"`
+	Duplicates int                        `json:"duplicates"` // 时间戳不晚于已处理上报而被丢弃的条数
+	Responses  map[string]*ReportResponse `json:"responses"`  // 按节点（NodeKey）合并的上报响应
+}
+
+// ReportResponse 上报响应数据
+type ReportResponse struct {
+	SpeedTestRequested    bool          `json:"speed_test_requested,omitempty"`
+	TopProcessesRequested bool          `json:"top_processes_requested,omitempty"` // 节点告警期间请求附带进程快照
+	Config                *PushedConfig `json:"config,omitempty"`                  // 与客户端已应用版本不同时下发
+	ServerTime            int64         `json:"server_time,omitempty"`             // 服务端处理上报时的Unix时间（秒），经中继或批量缓存的上报不返回
+}
+
+// ScrapeAck 服务端处理抓取结果后回传给客户端的响应（POST 到客户端抓取接口）
+type ScrapeAck struct {
+	Session            string          `json:"session"`
+	Seq                int64           `json:"seq"`
+	SpeedTestTimestamp int64           `json:"speed_test_timestamp,omitempty"` // 已记录的测速结果时间戳，客户端据此不再重复附带
+	Response           *ReportResponse `json:"response"`
 }
 
 // NodeStatus 节点状态
 type NodeStatus struct {
-	Hostname          string        `json:"hostname"`
-	LastSeen          time.Time     `json:"last_seen"`
-	Metrics           SystemMetrics `json:"metrics"`
-	IsOnline          bool          `json:"is_online"`
-	BandwidthAlerted  bool          `json:"bandwidth_alerted"`
-	CPUAlerted        bool          `json:"cpu_alerted"`        // CPU告警状态
-	MemoryAlerted     bool          `json:"memory_alerted"`     // 内存告警状态
-	ReportSamples     int           `json:"report_samples"`
-	LastThresholdMbps float64       `json:"last_threshold_mbps"`
+	NodeID            string            `json:"node_id,omitempty"`
+	Hostname          string            `json:"hostname"` // 客户端上报的主机名，可随客户端配置变化
+	Tags              map[string]string `json:"tags,omitempty"`
+	LastSeen          time.Time         `json:"last_seen"`
+	Metrics           SystemMetrics     `json:"metrics"`
+	IsOnline          bool              `json:"is_online"`
+	BandwidthAlerted  bool              `json:"bandwidth_alerted"`
+	CPUAlerted        bool              `json:"cpu_alerted"`    // CPU告警状态
+	MemoryAlerted     bool              `json:"memory_alerted"` // 内存告警状态
+	ReportSamples     int               `json:"report_samples"`
+	LastThresholdMbps float64           `json:"last_threshold_mbps"`
+	SpeedTest         *SpeedTestResult  `json:"speed_test,omitempty"`
+	SpeedTestPending  bool              `json:"speed_test_pending"`
+	SpeedTestAlerted  bool              `json:"speed_test_alerted"`
+
+	LastReportTimestamp int64  `json:"last_report_timestamp"` // 最近一次已处理上报的客户端时间戳
+	LastReportSession   string `json:"-"`                     // 最近一次已处理上报的 session 与序号，用于批量去重
+	LastReportSeq       int64  `json:"-"`
+	ScrapeFailures      int    `json:"scrape_failures,omitempty"` // 抓取模式下连续失败次数
+
+	LastHeartbeat time.Time `json:"last_heartbeat"` // 最近一次UDP心跳的服务端接收时间
+
+	ClockSkewSeconds int64 `json:"clock_skew_seconds"` // 客户端时钟减服务端时钟，正数表示客户端偏快
+	ClockSkewAlerted bool  `json:"clock_skew_alerted"`
+
+	RuleAlerts []string `json:"rule_alerts,omitempty"` // 正在告警的 alert_rules 规则（带宽、CPU、内存见对应字段）
+
+	HostnameConflict bool `json:"hostname_conflict"` // 有其他在线节点使用相同主机名
+
+	Via   string      `json:"via,omitempty"`   // 经由的中继主机名
+	Relay *RelayStats `json:"relay,omitempty"` // 节点作为中继时的运行状态
+
+	ConfigVersion        string `json:"config_version,omitempty"`         // 客户端已应用的下发配置版本
+	DesiredConfigVersion string `json:"desired_config_version,omitempty"` // 服务端期望的配置版本
+
+	DisplayName string `json:"display_name,omitempty"` // 管理员设置的显示名称，优先于主机名
+	State       string `json:"state,omitempty"`        // 管理状态，见 NodeState* 常量
+}
+
+// 节点管理状态
+const (
+	NodeStateActive         = ""               // 正常监控
+	NodeStateDisabled       = "disabled"       // 暂停告警，仍记录状态
+	NodeStateDecommissioned = "decommissioned" // 已退役，不再告警，等待删除
+)
+
+// ActiveAlerts 节点当前处于告警中的类型
+func (n *NodeStatus) ActiveAlerts() []string {
+	var alerts []string
+	if !n.IsOnline {
+		alerts = append(alerts, AlertOffline)
+	}
+	if n.BandwidthAlerted {
+		alerts = append(alerts, AlertBandwidth)
+	}
+	if n.CPUAlerted {
+		alerts = append(alerts, AlertCPU)
+	}
+	if n.MemoryAlerted {
+		alerts = append(alerts, AlertMemory)
+	}
+	alerts = append(alerts, n.RuleAlerts...)
+	if n.SpeedTestAlerted {
+		alerts = append(alerts, AlertSpeedTest)
+	}
+	if n.ClockSkewAlerted {
+		alerts = append(alerts, AlertClockSkew)
+	}
+	if n.HostnameConflict {
+		alerts = append(alerts, AlertHostnameConflict)
+	}
+	return alerts
+}
+
+// Key 返回节点在服务端的键，与 ReportRequest.NodeKey 一致
+func (n *NodeStatus) Key() string {
+	if n.NodeID != "" {
+		return n.NodeID
+	}
+	return n.Hostname
+}
+
+// Name 通知中使用的节点名称
+func (n *NodeStatus) Name() string {
+	if n.DisplayName != "" {
+		return n.DisplayName
+	}
+	return n.Hostname
+}
+
+// NodeList 带筛选、排序和分页的节点列表
+type NodeList struct {
+	Total  int          `json:"total"` // 筛选后、分页前的节点数
+	Offset int          `json:"offset"`
+	Limit  int          `json:"limit"` // 0表示不限制
+	Nodes  []NodeStatus `json:"nodes"`
+}
+
+// FleetSummary 全部节点的汇总统计
+type FleetSummary struct {
+	Total          int            `json:"total"`
+	Online         int            `json:"online"`
+	Offline        int            `json:"offline"`
+	Disabled       int            `json:"disabled"` // 已停用或退役的节点数
+	Alerting       int            `json:"alerting"` // 存在告警的在线节点数
+	Alerts         map[string]int `json:"alerts"`   // 按告警类型统计的节点数（含 offline）
+	NetworkInMbps  float64        `json:"network_in_mbps"`
+	NetworkOutMbps float64        `json:"network_out_mbps"`
+}
+
+// NodeUpdateRequest 管理接口修改节点的请求，未设置的字段保持不变
+type NodeUpdateRequest struct {
+	Node        string  `json:"node"` // 节点标识或主机名
+	DisplayName *string `json:"display_name,omitempty"`
+	State       *string `json:"state,omitempty"`
+}
+
+// 告警类型
+const (
+	AlertBandwidth        = "bandwidth"
+	AlertCPU              = "cpu"
+	AlertMemory           = "memory"
+	AlertSpeedTest        = "speed_test"
+	AlertOffline          = "offline"
+	AlertClockSkew        = "clock_skew"
+	AlertHostnameConflict = "hostname_conflict"
+)
+
+// 事件类型
+const (
+	EventNodeNew        = "node_new"
+	EventNodeOffline    = "node_offline"
+	EventNodeOnline     = "node_online"
+	EventAlertFiring    = "alert_firing"
+	EventAlertResolved  = "alert_resolved"
+	EventSilenceCreated = "silence_created"
+	EventNodeRemoved    = "node_removed"
+)
+
+// Event 节点状态变化事件
+type Event struct {
+	ID        int64       `json:"id"`
+	Type      string      `json:"type"`
+	Time      time.Time   `json:"time"`
+	Hostname  string      `json:"hostname,omitempty"`
+	Alert     string      `json:"alert,omitempty"`    // 告警类型（alert_firing/alert_resolved）
+	Severity  string      `json:"severity,omitempty"` // 规则告警的级别
+	Value     float64     `json:"value,omitempty"`
+	Threshold float64     `json:"threshold,omitempty"`
+	Node      *NodeStatus `json:"node,omitempty"`    // 事件发生时的完整节点状态
+	Silence   *Silence    `json:"silence,omitempty"` // silence_created 事件的静默规则
+}
+
+// Incident 一次告警从触发到恢复的记录
+type Incident struct {
+	ID         string     `json:"id"`
+	NodeID     string     `json:"node_id,omitempty"`
+	Hostname   string     `json:"hostname"`
+	Alert      string     `json:"alert"`
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"` // 未恢复时为空
+	Duration   float64    `json:"duration_seconds"`   // 未恢复时为截至查询时的时长
+	Threshold  float64    `json:"threshold"`
+	StartValue float64    `json:"start_value"`
+	PeakValue  float64    `json:"peak_value"` // 最严重的值：带宽、测速及低于阈值触发的规则取最小，其余取最大（时钟偏差按绝对值）
+	EndValue   float64    `json:"end_value,omitempty"`
+	Resolution string     `json:"resolution,omitempty"` // recovered、offline、removed、rule_removed 或 server_restart
+
+	Severity string            `json:"severity,omitempty"` // 规则告警的级别
+	Labels   map[string]string `json:"labels,omitempty"`   // 规则告警的标签
+}
+
+// 告警记录的结束原因
+const (
+	ResolutionRecovered     = "recovered"      // 指标恢复正常或节点重新上线
+	ResolutionOffline       = "offline"        // 节点离线，指标告警随之结束
+	ResolutionRemoved       = "removed"        // 节点被删除
+	ResolutionRuleRemoved   = "rule_removed"   // 告警规则被删除
+	ResolutionServerRestart = "server_restart" // 服务端重启时仍未结束
+)
+
+// Silence 告警静默规则，命中时不发送通知
+type Silence struct {
+	ID        string    `json:"id"`
+	Hostname  string    `json:"hostname"`        // 主机名glob，为空表示全部节点
+	Alert     string    `json:"alert,omitempty"` // 告警类型，为空表示全部类型
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+// SilenceRequest 创建静默规则请求
+type SilenceRequest struct {
+	Hostname        string `json:"hostname"`
+	Alert           string `json:"alert"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Comment         string `json:"comment"`
 }
 
 // APIResponse 通用API响应
@@ -94,26 +718,41 @@ type APIResponse struct {
 
 // LoadServerConfig 加载服务端配置并应用默认值
 func LoadServerConfig(path string) (*ServerConfig, error) {
-	data, err := os.ReadFile(path)
+	config, applied, err := readServerConfig(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var config ServerConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, err
-	}
-
 	// 自动应用默认值并保存配置文件
-	if applyServerDefaults(&config) {
-		if err := SaveServerConfig(path, &config); err != nil {
+	if applied {
+		if err := SaveServerConfig(path, config); err != nil {
 			log.Printf("保存配置默认值失败: %v", err)
 		} else {
 			log.Printf("配置文件已更新默认值: %s", path)
 		}
 	}
 
-	return &config, nil
+	return config, nil
+}
+
+// ReadServerConfig 读取服务端配置并应用默认值，不回写配置文件（用于热重载）
+func ReadServerConfig(path string) (*ServerConfig, error) {
+	config, _, err := readServerConfig(path)
+	return config, err
+}
+
+func readServerConfig(path string) (*ServerConfig, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var config ServerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, false, err
+	}
+
+	return &config, applyServerDefaults(&config), nil
 }
 
 // SaveServerConfig 保存服务端配置
@@ -126,8 +765,29 @@ func SaveServerConfig(path string, config *ServerConfig) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-// LoadClientConfig 加载客户端配置并应用默认值
+// LoadClientConfig 加载本地客户端配置并叠加保存的服务端下发配置
 func LoadClientConfig(path string) (*ClientConfig, error) {
+	config, err := LoadLocalClientConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 下发配置损坏时只使用本地配置，服务端会再次下发
+	pushedPath := PushedConfigPath(path)
+	pushed, err := LoadPushedConfig(pushedPath)
+	if err != nil {
+		log.Printf("忽略无法读取的下发配置 %s: %v", pushedPath, err)
+		return config, nil
+	}
+	if pushed != nil {
+		pushed.Config.Apply(config)
+		config.ConfigVersion = pushed.Version
+	}
+	return config, nil
+}
+
+// LoadLocalClientConfig 加载本地客户端配置文件并应用默认值，不包含服务端下发的配置
+func LoadLocalClientConfig(path string) (*ClientConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -160,59 +820,150 @@ func SaveClientConfig(path string, config *ClientConfig) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// PushedConfigPath 服务端下发配置的保存路径，与本地配置文件同目录，如 client.json 对应 client.pushed.json
+func PushedConfigPath(configPath string) string {
+	return strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".pushed.json"
+}
+
+// LoadPushedConfig 读取保存的下发配置，文件不存在时返回 nil
+func LoadPushedConfig(path string) (*PushedConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pushed PushedConfig
+	if err := json.Unmarshal(data, &pushed); err != nil {
+		return nil, err
+	}
+	return &pushed, nil
+}
+
+// SavePushedConfig 保存下发配置，本地配置文件保持运维人员编写的内容
+func SavePushedConfig(path string, pushed *PushedConfig) error {
+	data, err := json.MarshalIndent(pushed, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
 // applyServerDefaults 为服务端配置应用默认值
 func applyServerDefaults(config *ServerConfig) bool {
 	applied := false
-	
+
 	// 应用CPU阈值默认值
 	if config.Thresholds.CPUPercent <= 0 {
 		config.Thresholds.CPUPercent = 95.0
 		applied = true
 	}
-	
+
 	// 应用内存阈值默认值
 	if config.Thresholds.MemoryPercent <= 0 {
 		config.Thresholds.MemoryPercent = 95.0
 		applied = true
 	}
-	
+
 	// 应用带宽阈值默认值
 	if config.Thresholds.BandwidthMbps <= 0 {
 		config.Thresholds.BandwidthMbps = 100.0
 		applied = true
 	}
-	
+
 	// 应用离线阈值默认值
 	if config.Thresholds.OfflineSeconds <= 0 {
 		config.Thresholds.OfflineSeconds = 300
 		applied = true
 	}
-	
+
+	// 应用心跳超时默认值（负数表示关闭，保持原值）
+	if config.Thresholds.HeartbeatOfflineSeconds == 0 {
+		config.Thresholds.HeartbeatOfflineSeconds = 15
+		applied = true
+	}
+	if config.HeartbeatMaxSkewSeconds == 0 {
+		config.HeartbeatMaxSkewSeconds = 60
+		applied = true
+	}
+
+	// 应用时钟偏差阈值默认值（负数表示关闭，保持原值）
+	if config.Thresholds.ClockSkewSeconds == 0 {
+		config.Thresholds.ClockSkewSeconds = 30
+		applied = true
+	}
+
+	// 应用抓取参数默认值
+	if config.ScrapeIntervalSeconds <= 0 {
+		config.ScrapeIntervalSeconds = 30
+		applied = true
+	}
+	if config.ScrapeFailureLimit <= 0 {
+		config.ScrapeFailureLimit = 3
+		applied = true
+	}
+
+	// 应用告警规则级别默认值
+	for i := range config.AlertRules {
+		if config.AlertRules[i].Severity == "" {
+			config.AlertRules[i].Severity = SeverityWarning
+			applied = true
+		}
+	}
+
+	// 应用语言默认值
+	if config.Language == "" {
+		config.Language = "zh-CN"
+		applied = true
+	}
+
+	// 应用告警记录默认值
+	if config.IncidentFile == "" {
+		config.IncidentFile = "incidents.jsonl"
+		applied = true
+	}
+	if config.IncidentRetentionDays <= 0 {
+		config.IncidentRetentionDays = 90
+		applied = true
+	}
+	if config.SilenceFile == "" {
+		config.SilenceFile = "silences.json"
+		applied = true
+	}
+
 	// 应用监听地址默认值
 	if config.Listen == "" {
 		config.Listen = ":8080"
 		applied = true
 	}
-	
+
 	// 应用域名默认值
 	if config.Domain == "" {
 		config.Domain = "localhost"
 		applied = true
 	}
-	
+
+	// 应用日志默认值
+	if applyLogDefaults(&config.Log) {
+		applied = true
+	}
+
 	return applied
 }
 
 // applyClientDefaults 为客户端配置应用默认值
 func applyClientDefaults(config *ClientConfig) bool {
 	applied := false
-	
+
 	// 应用上报间隔默认值
 	if config.ReportIntervalSeconds <= 0 {
 		config.ReportIntervalSeconds = 60
 		applied = true
 	}
-	
+
 	// 应用主机名默认值
 	if config.Hostname == "" {
 		if hostname, err := os.Hostname(); err == nil {
@@ -222,7 +973,7 @@ func applyClientDefaults(config *ClientConfig) bool {
 		}
 		applied = true
 	}
-	
+
 	// 应用动态阈值默认配置
 	if len(config.Threshold.Dynamic) == 0 {
 		config.Threshold.Dynamic = []TimeWindowThreshold{
@@ -237,16 +988,59 @@ func applyClientDefaults(config *ClientConfig) bool {
 		config.Threshold.Dynamic = []TimeWindowThreshold{
 			{Start: "22:00", End: "02:00", BandwidthMbps: oldDynamic[0].BandwidthMbps}, // 高峰期
 			{Start: "02:00", End: "09:00", BandwidthMbps: oldDynamic[1].BandwidthMbps}, // 低谷期
-			{Start: "09:00", End: "22:00", BandwidthMbps: 100},                        // 新增平峰期
+			{Start: "09:00", End: "22:00", BandwidthMbps: 100},                         // 新增平峰期
 		}
 		applied = true
 	}
-	
+
+	// 应用网卡排除规则默认值（显式配置为空数组表示不排除）
+	if config.InterfaceFilter.Exclude == nil {
+		config.InterfaceFilter.Exclude = append([]string(nil), DefaultInterfaceExcludes...)
+		applied = true
+	}
+
+	// 应用进程快照默认值
+	if config.TopProcesses.Count <= 0 {
+		config.TopProcesses.Count = 5
+		applied = true
+	}
+	if config.TopProcesses.CPUPercent <= 0 {
+		config.TopProcesses.CPUPercent = 80
+		applied = true
+	}
+	if config.TopProcesses.MemoryPercent <= 0 {
+		config.TopProcesses.MemoryPercent = 80
+		applied = true
+	}
+
+	// 应用批量发送最长等待时间默认值（须小于服务端 offline_seconds，默认300秒）
+	if config.BatchSize > 1 && config.BatchMaxDelaySeconds <= 0 {
+		config.BatchMaxDelaySeconds = 120
+		applied = true
+	}
+
+	// 应用心跳间隔默认值
+	if config.HeartbeatIntervalSeconds <= 0 {
+		config.HeartbeatIntervalSeconds = 5
+		applied = true
+	}
+
+	// 应用测速时长默认值
+	if config.SpeedTest.DurationSeconds <= 0 {
+		config.SpeedTest.DurationSeconds = 5
+		applied = true
+	}
+
 	// 确保静态阈值有默认值（0表示禁用）
 	if config.Threshold.StaticBandwidthMbps < 0 {
 		config.Threshold.StaticBandwidthMbps = 0
 		applied = true
 	}
-	
+
+	// 应用日志默认值
+	if applyLogDefaults(&config.Log) {
+		applied = true
+	}
+
 	return applied
 }
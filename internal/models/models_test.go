@@ -0,0 +1,64 @@
+package models
+
+import "testing"
+
+func TestGRPCTLSEnabled(t *testing.T) {
+	tests := []struct {
+		address string
+		config  GRPCTLSConfig
+		want    bool
+	}{
+		{"monitor.example.com:9090", GRPCTLSConfig{}, true},
+		{"203.0.113.5:9090", GRPCTLSConfig{}, true},
+		{"localhost:9090", GRPCTLSConfig{}, false},
+		{"127.0.0.1:9090", GRPCTLSConfig{}, false},
+		{"[::1]:9090", GRPCTLSConfig{}, false},
+		{"127.0.0.1:9090", GRPCTLSConfig{ServerName: "monitor.example.com"}, true},
+		{"localhost:9090", GRPCTLSConfig{CAFile: "ca.crt"}, true},
+		{"monitor.example.com:9090", GRPCTLSConfig{Plaintext: true}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.config.Enabled(tt.address); got != tt.want {
+			t.Errorf("%s %+v: Enabled = %v，期望 %v", tt.address, tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestDashboardLink(t *testing.T) {
+	config := &ServerConfig{}
+	if link := config.DashboardLink("n1", "h1"); link != "" {
+		t.Errorf("未配置 dashboard_url 时应不生成链接，实际为 %q", link)
+	}
+
+	config.DashboardURL = "https://grafana.example.com/d/bm?var-node={node_id}&var-host={hostname}"
+	want := "https://grafana.example.com/d/bm?var-node=n1&var-host=web+1%26a"
+	if link := config.DashboardLink("n1", "web 1&a"); link != want {
+		t.Errorf("DashboardLink = %q，期望 %q", link, want)
+	}
+}
+
+func TestClockSkewDefault(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+		alert      bool
+	}{
+		{0, 30, true},
+		{10, 10, true},
+		{-1, -1, false},
+	}
+	for _, tt := range tests {
+		config := &ServerConfig{Thresholds: Threshold{ClockSkewSeconds: tt.configured}}
+		applyServerDefaults(config)
+		if config.Thresholds.ClockSkewSeconds != tt.want {
+			t.Errorf("clock_skew_seconds %d 应用默认值后为 %d，期望 %d", tt.configured, config.Thresholds.ClockSkewSeconds, tt.want)
+		}
+		alert := false
+		for _, rule := range config.EffectiveAlertRules() {
+			alert = alert || rule.Name == AlertClockSkew
+		}
+		if alert != tt.alert {
+			t.Errorf("clock_skew_seconds %d: 时钟偏差规则存在 = %v，期望 %v", tt.configured, alert, tt.alert)
+		}
+	}
+}
@@ -0,0 +1,613 @@
+package models
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/i18n"
+)
+
+// ConfigErrors 配置检查发现的全部问题，每条带字段路径
+type ConfigErrors []string
+
+func (e ConfigErrors) Error() string {
+	return strings.Join(e, "\n")
+}
+
+func (e *ConfigErrors) add(field, format string, args ...interface{}) {
+	*e = append(*e, field+": "+fmt.Sprintf(format, args...))
+}
+
+func (e ConfigErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// CheckServerConfigFile 检查服务端配置文件：JSON语法、未知字段、字段类型和取值，不修改文件
+func CheckServerConfigFile(path string) error {
+	var config ServerConfig
+	if err := checkConfigFile(path, &config); err != nil {
+		return err
+	}
+	applyServerDefaults(&config)
+	return config.Validate()
+}
+
+// CheckClientConfigFile 检查客户端配置文件：JSON语法、未知字段、字段类型和取值，不修改文件
+func CheckClientConfigFile(path string) error {
+	var config ClientConfig
+	if err := checkConfigFile(path, &config); err != nil {
+		return err
+	}
+	applyClientDefaults(&config)
+	return config.Validate()
+}
+
+// checkConfigFile 按配置结构体的JSON字段检查文件内容并解析到 config
+func checkConfigFile(path string, config interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := lineColumn(data, syntaxErr.Offset)
+			return fmt.Errorf("JSON语法错误（第%d行第%d列）: %v", line, col, err)
+		}
+		return fmt.Errorf("JSON解析失败: %v", err)
+	}
+
+	var errs ConfigErrors
+	checkSchema(&errs, "", tree, reflect.TypeOf(config).Elem())
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return json.Unmarshal(data, config)
+}
+
+// lineColumn 出错字符的行列号；SyntaxError.Offset 是已读取的字节数，出错字符为其前一个字节
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > 0 {
+		offset--
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// checkSchema 以Go结构体的JSON标签为模式，检查未知字段和取值类型
+func checkSchema(errs *ConfigErrors, field string, value interface{}, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if value == nil {
+		return // null 保持零值
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			errs.add(schemaField(field), "应为对象")
+			return
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinField(field, key)
+			fieldType, ok := fields[key]
+			if !ok {
+				errs.add(child, "未知字段")
+				continue
+			}
+			checkSchema(errs, child, object[key], fieldType)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			errs.add(schemaField(field), "应为对象")
+			return
+		}
+		for key, child := range object {
+			checkSchema(errs, joinField(field, key), child, t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]interface{})
+		if !ok {
+			errs.add(schemaField(field), "应为数组")
+			return
+		}
+		for i, child := range list {
+			checkSchema(errs, fmt.Sprintf("%s[%d]", field, i), child, t.Elem())
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			errs.add(schemaField(field), "应为字符串")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			errs.add(schemaField(field), "应为 true 或 false")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			errs.add(schemaField(field), "应为整数")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			errs.add(schemaField(field), "应为数字")
+		}
+	}
+}
+
+// jsonFields 返回结构体的 JSON字段名 -> 类型
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinField(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func schemaField(field string) string {
+	if field == "" {
+		return "(根)"
+	}
+	return field
+}
+
+// Validate 检查服务端配置中无法通过默认值修正的错误，返回全部问题
+func (c *ServerConfig) Validate() error {
+	var errs ConfigErrors
+
+	if c.Password == "" {
+		errs.add("password", "不能为空")
+	}
+	checkListenAddress(&errs, "listen", c.Listen)
+	if c.GRPCListen != "" {
+		checkListenAddress(&errs, "grpc_listen", c.GRPCListen)
+	}
+	checkGRPCServerTLS(&errs, c.GRPCListen, c.GRPCTLS)
+	if c.HeartbeatListen != "" {
+		checkListenAddress(&errs, "heartbeat_listen", c.HeartbeatListen)
+	}
+
+	if c.Telegram.BotToken != "" && c.Telegram.ChatID == 0 {
+		errs.add("telegram.chat_id", "已设置 bot_token 但未设置 chat_id")
+	}
+	if c.Telegram.BotToken == "" && c.Telegram.ChatID != 0 {
+		errs.add("telegram.bot_token", "已设置 chat_id 但未设置 bot_token")
+	}
+	checkLocale(&errs, "", c.Language, c.Timezone)
+	checkLocale(&errs, "telegram.", c.Telegram.Language, c.Telegram.Timezone)
+	checkAlertRules(&errs, c.AlertRules)
+	checkAlertTemplates(&errs, c.AlertTemplates, alertRuleNames(c.AlertRules))
+	if c.DashboardURL != "" {
+		checkHTTPURL(&errs, "dashboard_url", c.DashboardLink("sample", "sample-host"))
+	}
+
+	checkLogConfig(&errs, c.Log)
+	checkAggregate(&errs, "thresholds.bandwidth_aggregate", c.Thresholds.BandwidthAggregate)
+	checkAggregate(&errs, "thresholds.cpu_aggregate", c.Thresholds.CPUAggregate)
+
+	keys := make(map[string]string)
+	for i, key := range c.APIKeys {
+		field := fmt.Sprintf("api_keys[%d]", i)
+		switch key.Role {
+		case RoleReporter, RoleViewer, RoleAdmin:
+		default:
+			errs.add(field+".role", "角色 %q 无效，应为 reporter、viewer 或 admin", key.Role)
+		}
+		if key.Key == "" {
+			errs.add(field+".key", "不能为空")
+		} else if other, ok := keys[key.Key]; ok {
+			errs.add(field+".key", "与 %s 重复", other)
+		} else if key.Key == c.Password {
+			errs.add(field+".key", "与 password 相同")
+		} else {
+			keys[key.Key] = field
+		}
+	}
+
+	for i, target := range c.ScrapeTargets {
+		checkHTTPURL(&errs, fmt.Sprintf("scrape_targets[%d].url", i), target.URL)
+	}
+
+	for i, rule := range c.ClientConfigs {
+		field := fmt.Sprintf("client_configs[%d]", i)
+		for j, host := range rule.Hosts {
+			if _, err := path.Match(host, ""); err != nil {
+				errs.add(fmt.Sprintf("%s.hosts[%d]", field, j), "主机名规则 %q 无效", host)
+			}
+		}
+		if rule.Config.ReportIntervalSeconds != nil && *rule.Config.ReportIntervalSeconds <= 0 {
+			errs.add(field+".config.report_interval_seconds", "必须大于0")
+		}
+		if threshold := rule.Config.Threshold; threshold != nil {
+			checkThresholdSchedule(&errs, field+".config.threshold", *threshold)
+		}
+	}
+
+	return errs.err()
+}
+
+// Validate 检查客户端配置中无法通过默认值修正的错误，返回全部问题
+func (c *ClientConfig) Validate() error {
+	var errs ConfigErrors
+
+	if c.Password == "" {
+		errs.add("password", "不能为空")
+	}
+	switch c.Transport {
+	case "", TransportHTTP:
+		if c.ServerURL != "" {
+			checkHTTPURL(&errs, "server_url", c.ServerURL)
+		} else if c.ScrapeListen == "" {
+			errs.add("server_url", "不能为空（仅被抓取时需设置 scrape_listen）")
+		}
+	case TransportGRPC:
+		if c.GRPCAddress == "" {
+			errs.add("grpc_address", "transport 为 grpc 时不能为空")
+		} else {
+			checkHostPort(&errs, "grpc_address", c.GRPCAddress)
+		}
+		checkGRPCTLS(&errs, c.GRPCTLS)
+	default:
+		errs.add("transport", "%q 无效，应为 http 或 grpc", c.Transport)
+	}
+
+	switch c.Compression {
+	case "", "gzip", "zstd":
+	default:
+		errs.add("compression", "%q 无效，应为 gzip、zstd 或留空", c.Compression)
+	}
+
+	if c.ScrapeListen != "" {
+		checkListenAddress(&errs, "scrape_listen", c.ScrapeListen)
+	}
+	if c.HeartbeatAddress != "" {
+		checkHostPort(&errs, "heartbeat_address", c.HeartbeatAddress)
+	}
+	if c.Relay.Listen != "" {
+		checkListenAddress(&errs, "relay.listen", c.Relay.Listen)
+		if c.ServerURL == "" {
+			errs.add("relay.listen", "中继模式需要设置 server_url")
+		}
+	}
+
+	checkInterfacePatterns(&errs, "interface_filter.include", c.InterfaceFilter.Include)
+	checkInterfacePatterns(&errs, "interface_filter.exclude", c.InterfaceFilter.Exclude)
+
+	checkThresholdSchedule(&errs, "threshold", c.Threshold)
+	checkLogConfig(&errs, c.Log)
+
+	return errs.err()
+}
+
+// checkThresholdSchedule 检查动态阈值时段：时间格式、空时段、重叠，以及无静态阈值兜底时的空档
+func checkThresholdSchedule(errs *ConfigErrors, field string, threshold ClientThresholdConfig) {
+	const day = 24 * 60
+
+	// owners[m] 记录覆盖第 m 分钟的第一个时段
+	owners := make([]int, day)
+	for i := range owners {
+		owners[i] = -1
+	}
+	overlaps := make(map[[2]int]bool)
+	valid := 0
+
+	for i, w := range threshold.Dynamic {
+		windowField := fmt.Sprintf("%s.dynamic[%d]", field, i)
+		start, okStart := ParseHHMM(w.Start)
+		end, okEnd := ParseHHMM(w.End)
+		if !okStart {
+			errs.add(windowField+".start", "时间 %q 无效，应为 HH:MM（00:00-23:59）", w.Start)
+		}
+		if !okEnd {
+			errs.add(windowField+".end", "时间 %q 无效，应为 HH:MM（00:00-23:59）", w.End)
+		}
+		if w.BandwidthMbps <= 0 {
+			errs.add(windowField+".bandwidth_mbps", "必须大于0，否则该时段不生效")
+		}
+		if !okStart || !okEnd {
+			continue
+		}
+		if start == end {
+			errs.add(windowField, "开始与结束时间相同（%s），时段为空", w.Start)
+			continue
+		}
+
+		valid++
+		for m := start; m != end; m = (m + 1) % day {
+			if owner := owners[m]; owner >= 0 {
+				overlaps[[2]int{owner, i}] = true
+			} else {
+				owners[m] = i
+			}
+		}
+	}
+
+	pairs := make([][2]int, 0, len(overlaps))
+	for pair := range overlaps {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	for _, pair := range pairs {
+		a, b := threshold.Dynamic[pair[0]], threshold.Dynamic[pair[1]]
+		errs.add(fmt.Sprintf("%s.dynamic[%d]", field, pair[1]), "%s-%s 与 dynamic[%d] %s-%s 重叠，重叠部分只有前者生效",
+			b.Start, b.End, pair[0], a.Start, a.End)
+	}
+
+	// 有静态阈值时未覆盖的时段使用静态阈值，不算空档
+	if valid == 0 || threshold.StaticBandwidthMbps > 0 {
+		return
+	}
+	for _, gap := range uncoveredRanges(owners) {
+		errs.add(field+".dynamic", "%s 未被任何时段覆盖，且未设置 static_bandwidth_mbps，该时段不检测带宽", gap)
+	}
+}
+
+// uncoveredRanges 返回未被覆盖的分钟区间，如 "02:00-03:00"，跨午夜的区间合并为一段
+func uncoveredRanges(owners []int) []string {
+	day := len(owners)
+
+	// 从一个已覆盖的分钟开始扫描，使跨午夜的空档连续
+	first := -1
+	for m, owner := range owners {
+		if owner >= 0 {
+			first = m
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	var ranges []string
+	start := -1
+	for i := 1; i <= day; i++ {
+		m := (first + i) % day
+		if owners[m] < 0 {
+			if start < 0 {
+				start = m
+			}
+			continue
+		}
+		if start >= 0 {
+			ranges = append(ranges, formatHHMM(start)+"-"+formatHHMM(m))
+			start = -1
+		}
+	}
+	return ranges
+}
+
+// ParseHHMM 解析 HH:MM 格式的时间，返回当天的分钟数
+func ParseHHMM(s string) (int, bool) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, false
+	}
+	for _, i := range []int{0, 1, 3, 4} {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+	}
+	h := int(s[0]-'0')*10 + int(s[1]-'0')
+	m := int(s[3]-'0')*10 + int(s[4]-'0')
+	if h > 23 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func formatHHMM(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// checkLocale 检查语言和时区，留空表示使用默认值
+func checkLocale(errs *ConfigErrors, prefix, language, timezone string) {
+	if language != "" {
+		if _, ok := i18n.ParseLang(language); !ok {
+			errs.add(prefix+"language", "不支持的语言 %q，应为 %s", language, joinLangs(i18n.Supported()))
+		}
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			errs.add(prefix+"timezone", "无效的时区 %q，应为 IANA 时区名称，如 Asia/Shanghai", timezone)
+		}
+	}
+}
+
+func joinLangs(langs []i18n.Lang) string {
+	names := make([]string, len(langs))
+	for i, lang := range langs {
+		names[i] = string(lang)
+	}
+	return strings.Join(names, "、")
+}
+
+func checkLogConfig(errs *ConfigErrors, config LogConfig) {
+	switch strings.ToLower(config.Level) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		errs.add("log.level", "%q 无效，应为 debug、info、warn 或 error", config.Level)
+	}
+	switch config.Format {
+	case LogFormatText, LogFormatJSON:
+	default:
+		errs.add("log.format", "%q 无效，应为 text 或 json", config.Format)
+	}
+}
+
+func checkAggregate(errs *ConfigErrors, field, value string) {
+	switch value {
+	case "", AggregateCurrent, AggregateMin, AggregateAvg, AggregateMax, AggregateP95:
+	default:
+		errs.add(field, "%q 无效，应为 current、min、avg、max 或 p95", value)
+	}
+}
+
+// checkHTTPURL 检查地址是否为可访问的 http(s) URL
+func checkHTTPURL(errs *ConfigErrors, field, value string) {
+	u, err := url.Parse(value)
+	if err != nil {
+		errs.add(field, "%q 无效: %v", value, err)
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		errs.add(field, "%q 缺少 http:// 或 https:// 前缀", value)
+		return
+	}
+	if u.Hostname() == "" {
+		errs.add(field, "%q 缺少主机名", value)
+		return
+	}
+	if port := u.Port(); port != "" {
+		checkPort(errs, field, port)
+	}
+}
+
+// checkListenAddress 检查监听地址，主机部分可为空（如 ":8080"）
+func checkListenAddress(errs *ConfigErrors, field, value string) {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		errs.add(field, "%q 无效，应为 host:port 或 :port", value)
+		return
+	}
+	checkPort(errs, field, port)
+}
+
+// checkTLSFiles 证书和私钥须同时设置且能成功加载
+func checkTLSFiles(errs *ConfigErrors, field string, files TLSFiles) {
+	switch {
+	case files.CertFile == "" && files.KeyFile == "":
+	case files.CertFile == "":
+		errs.add(field+".cert_file", "已设置 key_file 但未设置 cert_file")
+	case files.KeyFile == "":
+		errs.add(field+".key_file", "已设置 cert_file 但未设置 key_file")
+	default:
+		if _, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile); err != nil {
+			errs.add(field, "无法加载证书: %v", err)
+		}
+	}
+}
+
+// checkGRPCServerTLS 客户端连接非回环地址时默认使用TLS，服务端在非回环地址上监听须配置证书或显式声明明文
+func checkGRPCServerTLS(errs *ConfigErrors, listen string, files TLSFiles) {
+	if files.Plaintext {
+		if files.CertFile != "" || files.KeyFile != "" {
+			errs.add("grpc_tls.plaintext", "为 true 时不能同时设置 cert_file 或 key_file")
+		}
+		return
+	}
+	checkTLSFiles(errs, "grpc_tls", files)
+
+	host, _, err := net.SplitHostPort(listen)
+	if listen == "" || err != nil || files.CertFile != "" || isLoopbackHost(host) {
+		return
+	}
+	errs.add("grpc_tls", "grpc_listen %s 不是回环地址，须设置 cert_file 和 key_file，或设置 plaintext: true 显式使用明文", listen)
+}
+
+// checkGRPCTLS 检查客户端gRPC的CA文件，明文连接时不能同时设置CA或主机名
+func checkGRPCTLS(errs *ConfigErrors, config GRPCTLSConfig) {
+	if config.Plaintext && (config.CAFile != "" || config.ServerName != "") {
+		errs.add("grpc_tls.plaintext", "为 true 时不能同时设置 ca_file 或 server_name")
+	}
+	if config.CAFile != "" {
+		if _, err := LoadCertPool(config.CAFile); err != nil {
+			errs.add("grpc_tls.ca_file", "%v", err)
+		}
+	}
+}
+
+// LoadCertPool 读取PEM格式的CA证书
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取CA证书失败: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s 中没有有效的PEM证书", path)
+	}
+	return pool, nil
+}
+
+// checkHostPort 检查连接地址，主机部分不能为空
+func checkHostPort(errs *ConfigErrors, field, value string) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil || host == "" {
+		errs.add(field, "%q 无效，应为 host:port", value)
+		return
+	}
+	checkPort(errs, field, port)
+}
+
+func checkPort(errs *ConfigErrors, field, port string) {
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		errs.add(field, "端口 %q 无效", port)
+	}
+}
+
+func checkInterfacePatterns(errs *ConfigErrors, field string, patterns []string) {
+	for i, p := range patterns {
+		if expr, ok := strings.CutPrefix(p, "re:"); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				errs.add(fmt.Sprintf("%s[%d]", field, i), "正则规则 %q 无效: %v", p, err)
+			}
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			errs.add(fmt.Sprintf("%s[%d]", field, i), "glob 规则 %q 无效", p)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHHMM(t *testing.T) {
+	tests := []struct {
+		value   string
+		minutes int
+		ok      bool
+	}{
+		{"00:00", 0, true},
+		{"09:30", 570, true},
+		{"23:59", 1439, true},
+		{"24:00", 0, false},
+		{"12:60", 0, false},
+		{"9:00", 0, false},
+		{"09-00", 0, false},
+		{"0a:00", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		minutes, ok := ParseHHMM(tt.value)
+		if ok != tt.ok || (ok && minutes != tt.minutes) {
+			t.Errorf("ParseHHMM(%q) = %d, %v，期望 %d, %v", tt.value, minutes, ok, tt.minutes, tt.ok)
+		}
+	}
+}
+
+func TestCheckThresholdSchedule(t *testing.T) {
+	window := func(start, end string) TimeWindowThreshold {
+		return TimeWindowThreshold{Start: start, End: end, BandwidthMbps: 100}
+	}
+
+	tests := []struct {
+		name      string
+		threshold ClientThresholdConfig
+		want      []string // 每条错误应包含的内容，按顺序；为空表示没有错误
+	}{
+		{"无动态时段", ClientThresholdConfig{StaticBandwidthMbps: 100}, nil},
+		{"跨午夜时段拼满全天", ClientThresholdConfig{
+			Dynamic: []TimeWindowThreshold{window("09:00", "22:00"), window("22:00", "09:00")},
+		}, nil},
+		{"单个跨午夜时段由静态阈值兜底", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{window("22:00", "06:00")},
+		}, nil},
+		{"首尾相接不算重叠", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{window("08:00", "12:00"), window("12:00", "18:00")},
+		}, nil},
+		{"重叠", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{window("08:00", "12:00"), window("11:00", "13:00")},
+		}, []string{"threshold.dynamic[1]: 11:00-13:00 与 dynamic[0] 08:00-12:00 重叠"}},
+		{"跨午夜重叠", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{window("22:00", "02:00"), window("01:00", "03:00")},
+		}, []string{"threshold.dynamic[1]: 01:00-03:00 与 dynamic[0] 22:00-02:00 重叠"}},
+		{"空档", ClientThresholdConfig{
+			Dynamic: []TimeWindowThreshold{window("09:00", "22:00")},
+		}, []string{"threshold.dynamic: 22:00-09:00 未被任何时段覆盖"}},
+		{"多个空档，跨午夜的空档合并为一段", ClientThresholdConfig{
+			Dynamic: []TimeWindowThreshold{window("02:00", "03:00"), window("05:00", "06:00")},
+		}, []string{"03:00-05:00 未被任何时段覆盖", "06:00-02:00 未被任何时段覆盖"}},
+		{"无效时间", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{window("24:00", "9:00")},
+		}, []string{"threshold.dynamic[0].start: 时间 \"24:00\" 无效", "threshold.dynamic[0].end: 时间 \"9:00\" 无效"}},
+		{"空时段", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{window("08:00", "08:00")},
+		}, []string{"threshold.dynamic[0]: 开始与结束时间相同"}},
+		{"带宽为0", ClientThresholdConfig{
+			StaticBandwidthMbps: 50,
+			Dynamic:             []TimeWindowThreshold{{Start: "08:00", End: "09:00"}},
+		}, []string{"threshold.dynamic[0].bandwidth_mbps: 必须大于0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs ConfigErrors
+			checkThresholdSchedule(&errs, "threshold", tt.threshold)
+			checkErrors(t, errs, tt.want)
+		})
+	}
+}
+
+// checkErrors 逐条比较错误，want[i] 须是 errs[i] 的一部分
+func checkErrors(t *testing.T, errs ConfigErrors, want []string) {
+	t.Helper()
+	if len(errs) != len(want) {
+		t.Fatalf("得到 %d 条错误，期望 %d 条:\n%v", len(errs), len(want), errs)
+	}
+	for i := range want {
+		if !strings.Contains(errs[i], want[i]) {
+			t.Errorf("第 %d 条错误 %q 不包含 %q", i, errs[i], want[i])
+		}
+	}
+}
+
+func TestServerConfigValidate(t *testing.T) {
+	validConfig := func() *ServerConfig {
+		config := &ServerConfig{Password: "pw"}
+		applyServerDefaults(config)
+		return config
+	}
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("默认配置应有效: %v", err)
+	}
+
+	tests := []struct {
+		field  string
+		modify func(c *ServerConfig)
+	}{
+		{"password", func(c *ServerConfig) { c.Password = "" }},
+		{"listen", func(c *ServerConfig) { c.Listen = "8080" }},
+		{"listen", func(c *ServerConfig) { c.Listen = ":70000" }},
+		{"grpc_listen", func(c *ServerConfig) { c.GRPCListen = "localhost" }},
+		{"grpc_tls.key_file", func(c *ServerConfig) { c.GRPCTLS.CertFile = "server.crt" }},
+		{"grpc_tls", func(c *ServerConfig) { c.GRPCListen = ":9090" }},
+		{"grpc_tls", func(c *ServerConfig) { c.GRPCListen = "10.0.0.1:9090" }},
+		{"grpc_tls.plaintext", func(c *ServerConfig) {
+			c.GRPCTLS = TLSFiles{CertFile: "server.crt", KeyFile: "server.key", Plaintext: true}
+		}},
+		{"heartbeat_listen", func(c *ServerConfig) { c.HeartbeatListen = ":0" }},
+		{"telegram.chat_id", func(c *ServerConfig) { c.Telegram.BotToken = "token" }},
+		{"telegram.bot_token", func(c *ServerConfig) { c.Telegram.ChatID = 1 }},
+		{"language", func(c *ServerConfig) { c.Language = "fr" }},
+		{"timezone", func(c *ServerConfig) { c.Timezone = "Mars/Base" }},
+		{"telegram.timezone", func(c *ServerConfig) { c.Telegram.Timezone = "UTC+8" }},
+		{"alert_rules[0].expr", func(c *ServerConfig) {
+			c.AlertRules = []AlertRule{{Name: "busy", Expr: "cpu >", Severity: SeverityWarning}}
+		}},
+		{"alert_templates.nope", func(c *ServerConfig) {
+			c.AlertTemplates = map[string]AlertTemplate{"nope": {Firing: "x"}}
+		}},
+		{"dashboard_url", func(c *ServerConfig) { c.DashboardURL = "grafana/{node_id}" }},
+		{"log.level", func(c *ServerConfig) { c.Log.Level = "verbose" }},
+		{"log.format", func(c *ServerConfig) { c.Log.Format = "xml" }},
+		{"thresholds.bandwidth_aggregate", func(c *ServerConfig) { c.Thresholds.BandwidthAggregate = "median" }},
+		{"thresholds.cpu_aggregate", func(c *ServerConfig) { c.Thresholds.CPUAggregate = "p99" }},
+		{"api_keys[0].role", func(c *ServerConfig) { c.APIKeys = []APIKey{{Key: "k", Role: "root"}} }},
+		{"api_keys[0].key", func(c *ServerConfig) { c.APIKeys = []APIKey{{Role: RoleAdmin}} }},
+		{"api_keys[0].key", func(c *ServerConfig) { c.APIKeys = []APIKey{{Key: "pw", Role: RoleAdmin}} }},
+		{"api_keys[1].key", func(c *ServerConfig) {
+			c.APIKeys = []APIKey{{Key: "k", Role: RoleAdmin}, {Key: "k", Role: RoleViewer}}
+		}},
+		{"scrape_targets[0].url", func(c *ServerConfig) { c.ScrapeTargets = []ScrapeTarget{{URL: "10.0.0.5:9101"}} }},
+		{"client_configs[0].hosts[1]", func(c *ServerConfig) {
+			c.ClientConfigs = []ClientConfigRule{{Hosts: []string{"web-*", "db-["}}}
+		}},
+		{"client_configs[0].config.report_interval_seconds", func(c *ServerConfig) {
+			interval := 0
+			c.ClientConfigs = []ClientConfigRule{{Config: ManagedClientConfig{ReportIntervalSeconds: &interval}}}
+		}},
+		{"client_configs[0].config.threshold.dynamic", func(c *ServerConfig) {
+			c.ClientConfigs = []ClientConfigRule{{Config: ManagedClientConfig{Threshold: &ClientThresholdConfig{
+				Dynamic: []TimeWindowThreshold{{Start: "09:00", End: "18:00", BandwidthMbps: 100}},
+			}}}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			config := validConfig()
+			tt.modify(config)
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("期望校验失败")
+			}
+			errs := err.(ConfigErrors)
+			if len(errs) != 1 || !strings.HasPrefix(errs[0], tt.field+":") {
+				t.Errorf("错误应只涉及 %s，实际为:\n%v", tt.field, errs)
+			}
+		})
+	}
+
+	// 回环地址、显式明文均可不配置证书
+	for _, tls := range []struct {
+		listen string
+		files  TLSFiles
+	}{
+		{"127.0.0.1:9090", TLSFiles{}},
+		{"localhost:9090", TLSFiles{}},
+		{"[::1]:9090", TLSFiles{}},
+		{":9090", TLSFiles{Plaintext: true}},
+	} {
+		config := validConfig()
+		config.GRPCListen, config.GRPCTLS = tls.listen, tls.files
+		if err := config.Validate(); err != nil {
+			t.Errorf("grpc_listen %s plaintext=%v: %v", tls.listen, tls.files.Plaintext, err)
+		}
+	}
+
+	// 合法的 glob 与按主机名分组的配置不应报错
+	config := validConfig()
+	interval := 30
+	config.ClientConfigs = []ClientConfigRule{
+		{Hosts: []string{"CN-GZ-*", "db-[0-9]", "web-?"}, Config: ManagedClientConfig{ReportIntervalSeconds: &interval}},
+		{Hosts: nil},
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("client_configs 应有效: %v", err)
+	}
+}
+
+func TestClientConfigValidate(t *testing.T) {
+	validConfig := func() *ClientConfig {
+		config := &ClientConfig{Password: "pw", ServerURL: "http://monitor.example.com:8080"}
+		applyClientDefaults(config)
+		return config
+	}
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("默认配置应有效: %v", err)
+	}
+
+	tests := []struct {
+		field  string
+		modify func(c *ClientConfig)
+	}{
+		{"password", func(c *ClientConfig) { c.Password = "" }},
+		{"server_url", func(c *ClientConfig) { c.ServerURL = "" }},
+		{"server_url", func(c *ClientConfig) { c.ServerURL = "monitor.example.com:8080" }},
+		{"server_url", func(c *ClientConfig) { c.ServerURL = "http://:8080" }},
+		{"transport", func(c *ClientConfig) { c.Transport = "udp" }},
+		{"grpc_address", func(c *ClientConfig) { c.Transport = TransportGRPC }},
+		{"grpc_address", func(c *ClientConfig) { c.Transport, c.GRPCAddress = TransportGRPC, ":9090" }},
+		{"compression", func(c *ClientConfig) { c.Compression = "brotli" }},
+		{"scrape_listen", func(c *ClientConfig) { c.ScrapeListen = "9101" }},
+		{"heartbeat_address", func(c *ClientConfig) { c.HeartbeatAddress = ":9102" }},
+		{"relay.listen", func(c *ClientConfig) { c.Relay.Listen = "9200" }},
+		{"relay.listen", func(c *ClientConfig) { c.ServerURL, c.ScrapeListen, c.Relay.Listen = "", ":9101", ":9200" }},
+		{"interface_filter.include[0]", func(c *ClientConfig) { c.InterfaceFilter.Include = []string{"eth["} }},
+		{"interface_filter.exclude[1]", func(c *ClientConfig) { c.InterfaceFilter.Exclude = []string{"re:^veth", "re:(docker"} }},
+		{"threshold.dynamic[0].end", func(c *ClientConfig) {
+			c.Threshold = ClientThresholdConfig{StaticBandwidthMbps: 10, Dynamic: []TimeWindowThreshold{{Start: "08:00", End: "25:00", BandwidthMbps: 1}}}
+		}},
+		{"log.level", func(c *ClientConfig) { c.Log.Level = "trace" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			config := validConfig()
+			tt.modify(config)
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("期望校验失败")
+			}
+			errs := err.(ConfigErrors)
+			if len(errs) != 1 || !strings.HasPrefix(errs[0], tt.field+":") {
+				t.Errorf("错误应只涉及 %s，实际为:\n%v", tt.field, errs)
+			}
+		})
+	}
+
+	// 仅被抓取的节点不需要 server_url
+	config := validConfig()
+	config.ServerURL, config.ScrapeListen = "", ":9101"
+	if err := config.Validate(); err != nil {
+		t.Errorf("仅抓取的配置应有效: %v", err)
+	}
+}
+
+func TestCheckServerConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"有效", `{"password": "pw"}`, ""},
+		{"语法错误", "{\n  \"password\": \"pw\",\n}", "JSON语法错误（第3行第1列）"},
+		{"未知字段", `{"password": "pw", "thresholds": {"cpu": 90}}`, "thresholds.cpu: 未知字段"},
+		{"类型错误", `{"password": "pw", "thresholds": {"offline_seconds": 1.5}}`, "thresholds.offline_seconds: 应为整数"},
+		{"数组类型", `{"password": "pw", "api_keys": {"key": "k"}}`, "api_keys: 应为数组"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := CheckServerConfigFile(path)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("期望有效，实际为 %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("错误 %v 不包含 %q", err, tt.want)
+			}
+		})
+	}
+}
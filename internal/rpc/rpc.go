@@ -0,0 +1,170 @@
+// Package rpc 定义上报与状态订阅的 gRPC 服务。
+//
+// 消息直接复用 models 中的结构体，通过注册的 JSON 编解码器传输，
+// 因此不需要 protoc 生成代码；服务描述按 protoc-gen-go-grpc 的约定手写。
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"bandwidth-monitor/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName JSON 编解码器名称，客户端需通过 grpc.CallContentSubtype 指定
+const CodecName = "json"
+
+// ServiceName gRPC 服务全名
+const ServiceName = "bandwidthmonitor.Monitor"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return CodecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// WatchRequest 状态订阅请求
+type WatchRequest struct {
+	Password string `json:"password"`
+	Hostname string `json:"hostname,omitempty"` // 为空表示订阅全部节点
+}
+
+// MonitorServer 服务端需实现的接口
+type MonitorServer interface {
+	// Report 客户端在一个长连接上持续发送上报；服务端逐条返回响应，用于下发测速请求与集中配置
+	Report(Monitor_ReportServer) error
+	// WatchStatus 先推送当前全部节点状态，之后推送每次状态变化
+	WatchStatus(*WatchRequest, Monitor_WatchStatusServer) error
+}
+
+// Monitor_ReportServer 服务端的上报流
+type Monitor_ReportServer interface {
+	Send(*models.ReportResponse) error
+	Recv() (*models.ReportRequest, error)
+	grpc.ServerStream
+}
+
+type reportServer struct{ grpc.ServerStream }
+
+func (s *reportServer) Send(m *models.ReportResponse) error { return s.ServerStream.SendMsg(m) }
+func (s *reportServer) Recv() (*models.ReportRequest, error) {
+	m := new(models.ReportRequest)
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Monitor_WatchStatusServer 服务端的状态推送流
+type Monitor_WatchStatusServer interface {
+	Send(*models.NodeStatus) error
+	grpc.ServerStream
+}
+
+type watchStatusServer struct{ grpc.ServerStream }
+
+func (s *watchStatusServer) Send(m *models.NodeStatus) error { return s.ServerStream.SendMsg(m) }
+
+func reportHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MonitorServer).Report(&reportServer{stream})
+}
+
+func watchStatusHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(WatchRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(MonitorServer).WatchStatus(req, &watchStatusServer{stream})
+}
+
+// ServiceDesc Monitor 服务描述
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*MonitorServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Report", Handler: reportHandler, ServerStreams: true, ClientStreams: true},
+		{StreamName: "WatchStatus", Handler: watchStatusHandler, ServerStreams: true},
+	},
+	Metadata: "bandwidth-monitor/internal/rpc",
+}
+
+// RegisterMonitorServer 注册服务实现
+func RegisterMonitorServer(s *grpc.Server, srv MonitorServer) {
+	s.RegisterService(&ServiceDesc, srv)
+}
+
+// MonitorClient 客户端存根
+type MonitorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMonitorClient(cc *grpc.ClientConn) *MonitorClient {
+	return &MonitorClient{cc: cc}
+}
+
+// Monitor_ReportClient 客户端的上报流
+type Monitor_ReportClient interface {
+	Send(*models.ReportRequest) error
+	Recv() (*models.ReportResponse, error)
+	grpc.ClientStream
+}
+
+type reportClient struct{ grpc.ClientStream }
+
+func (c *reportClient) Send(m *models.ReportRequest) error { return c.ClientStream.SendMsg(m) }
+func (c *reportClient) Recv() (*models.ReportResponse, error) {
+	m := new(models.ReportResponse)
+	if err := c.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Report 打开上报流
+func (c *MonitorClient) Report(ctx context.Context) (Monitor_ReportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceDesc.Streams[0], "/"+ServiceName+"/Report",
+		grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return nil, err
+	}
+	return &reportClient{stream}, nil
+}
+
+// Monitor_WatchStatusClient 客户端的状态订阅流
+type Monitor_WatchStatusClient interface {
+	Recv() (*models.NodeStatus, error)
+	grpc.ClientStream
+}
+
+type watchStatusClient struct{ grpc.ClientStream }
+
+func (c *watchStatusClient) Recv() (*models.NodeStatus, error) {
+	m := new(models.NodeStatus)
+	if err := c.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WatchStatus 订阅节点状态变化
+func (c *MonitorClient) WatchStatus(ctx context.Context, req *WatchRequest) (Monitor_WatchStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceDesc.Streams[1], "/"+ServiceName+"/WatchStatus",
+		grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &watchStatusClient{stream}, nil
+}
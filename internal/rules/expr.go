@@ -0,0 +1,157 @@
+package rules
+
+import "math"
+
+// valueKind 表达式的值类型，解析时检查，求值时不再出现类型错误
+type valueKind int
+
+const (
+	kindNumber valueKind = iota
+	kindString
+	kindBool
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindNumber:
+		return "数值"
+	case kindString:
+		return "字符串"
+	}
+	return "布尔值"
+}
+
+type value struct {
+	num float64
+	str string
+	b   bool
+}
+
+// node 表达式语法树节点
+type node interface {
+	kind() valueKind
+	eval(env Env) (value, bool)
+}
+
+type numberLit struct{ v float64 }
+
+func (n *numberLit) kind() valueKind        { return kindNumber }
+func (n *numberLit) eval(Env) (value, bool) { return value{num: n.v}, true }
+
+type stringLit struct{ v string }
+
+func (n *stringLit) kind() valueKind        { return kindString }
+func (n *stringLit) eval(Env) (value, bool) { return value{str: n.v}, true }
+
+type boolLit struct{ v bool }
+
+func (n *boolLit) kind() valueKind        { return kindBool }
+func (n *boolLit) eval(Env) (value, bool) { return value{b: n.v}, true }
+
+// variable 指标变量
+type variable struct{ name string }
+
+func (n *variable) kind() valueKind { return kindNumber }
+func (n *variable) eval(env Env) (value, bool) {
+	v, ok := env.Number(n.name)
+	return value{num: v}, ok
+}
+
+// tagRef 节点标签，如 tag.role
+type tagRef struct{ key string }
+
+func (n *tagRef) kind() valueKind { return kindString }
+func (n *tagRef) eval(env Env) (value, bool) {
+	return value{str: env.Tag(n.key)}, true
+}
+
+// unary 取反（!）与取负（-）
+type unary struct {
+	op string
+	x  node
+}
+
+func (n *unary) kind() valueKind { return n.x.kind() }
+func (n *unary) eval(env Env) (value, bool) {
+	v, ok := n.x.eval(env)
+	if !ok {
+		return value{}, false
+	}
+	if n.op == "!" {
+		return value{b: !v.b}, true
+	}
+	return value{num: -v.num}, true
+}
+
+// binary 逻辑、比较与算术运算
+type binary struct {
+	op   string
+	x, y node
+}
+
+func (n *binary) kind() valueKind {
+	switch n.op {
+	case "+", "-", "*", "/":
+		return kindNumber
+	}
+	return kindBool
+}
+
+func (n *binary) eval(env Env) (value, bool) {
+	x, ok := n.x.eval(env)
+	if !ok {
+		return value{}, false
+	}
+
+	// 逻辑运算短路求值，未求值一侧的变量不可用时不影响结果
+	switch n.op {
+	case "&&":
+		if !x.b {
+			return value{b: false}, true
+		}
+		return n.y.eval(env)
+	case "||":
+		if x.b {
+			return value{b: true}, true
+		}
+		return n.y.eval(env)
+	}
+
+	y, ok := n.y.eval(env)
+	if !ok {
+		return value{}, false
+	}
+	if n.x.kind() == kindString {
+		if n.op == "==" {
+			return value{b: x.str == y.str}, true
+		}
+		return value{b: x.str != y.str}, true
+	}
+
+	switch n.op {
+	case "+":
+		return value{num: x.num + y.num}, true
+	case "-":
+		return value{num: x.num - y.num}, true
+	case "*":
+		return value{num: x.num * y.num}, true
+	case "/":
+		if y.num == 0 {
+			return value{num: math.NaN()}, true // 与NaN的比较均不成立
+		}
+		return value{num: x.num / y.num}, true
+	case "==":
+		return value{b: x.num == y.num}, true
+	case "!=":
+		return value{b: x.num != y.num}, true
+	case "<":
+		return value{b: x.num < y.num}, true
+	case "<=":
+		return value{b: x.num <= y.num}, true
+	case ">":
+		return value{b: x.num > y.num}, true
+	case ">=":
+		return value{b: x.num >= y.num}, true
+	}
+	return value{}, false
+}
@@ -0,0 +1,337 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokDuration // 带单位的数字，仅用于 for 子句，如 5m
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 从1开始的字符位置，用于错误提示
+}
+
+// 运算符，两个字符的在前以便优先匹配
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")", "."}
+
+// parser 递归下降解析，优先级从低到高：|| && ! 比较 加减 乘除 取负
+type parser struct {
+	tokens []token
+	pos    int
+	vars   []string
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("表达式末尾: "+format, args...)
+	}
+	return fmt.Errorf("第%d个字符: "+format, append([]interface{}{t.pos}, args...)...)
+}
+
+func (p *parser) lex(source string) error {
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+			continue
+
+		case isDigit(r):
+			for i < len(runes) && (isDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// 指数部分，如 1e6、2.5E-3；e 后没有数字时按时长单位处理
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && isDigit(runes[j]) {
+					i = j
+					for i < len(runes) && isDigit(runes[i]) {
+						i++
+					}
+				}
+			}
+			kind := tokNumber
+			for i < len(runes) && (isLetter(runes[i]) || isDigit(runes[i]) || runes[i] == '.') {
+				kind = tokDuration
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind, string(runes[start:i]), start + 1})
+			continue
+
+		case isLetter(r):
+			for i < len(runes) && (isLetter(runes[i]) || isDigit(runes[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{tokIdent, string(runes[start:i]), start + 1})
+			continue
+
+		case r == '"' || r == '\'':
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return fmt.Errorf("第%d个字符: 字符串缺少结束引号", start+1)
+			}
+			i++
+			text := string(runes[start:i])
+			if r == '\'' {
+				text = `"` + strings.ReplaceAll(strings.ReplaceAll(text[1:len(text)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			s, err := strconv.Unquote(text)
+			if err != nil {
+				return fmt.Errorf("第%d个字符: 无效的字符串 %s", start+1, string(runes[start:i]))
+			}
+			p.tokens = append(p.tokens, token{tokString, s, start + 1})
+			continue
+		}
+
+		matched := false
+		for _, op := range operators {
+			if strings.HasPrefix(string(runes[i:]), op) {
+				p.tokens = append(p.tokens, token{tokOp, op, start + 1})
+				i += len([]rune(op))
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("第%d个字符: 无法识别的字符 %q", start+1, r)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokEOF, pos: len(runes) + 1})
+	return nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept 下一个记号是给定运算符之一时消费并返回
+func (p *parser) accept(ops ...string) (token, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return t, false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return p.next(), true
+		}
+	}
+	return t, false
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseLogical("&&", p.parseNot)
+}
+
+func (p *parser) parseLogical(op string, operand func() (node, error)) (node, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.accept(op)
+		if !ok {
+			return x, nil
+		}
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if x.kind() != kindBool || y.kind() != kindBool {
+			return nil, p.errorf(t, "%s 两侧应为布尔值（比较表达式）", op)
+		}
+		x = &binary{op: op, x: x, y: y}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	t, ok := p.accept("!")
+	if !ok {
+		return p.parseComparison()
+	}
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	if x.kind() != kindBool {
+		return nil, p.errorf(t, "! 只能用于布尔值")
+	}
+	return &unary{op: "!", x: x}, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	x, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := p.accept("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return x, nil
+	}
+	y, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case x.kind() != y.kind():
+		return nil, p.errorf(t, "%s 两侧类型不同（%s 与 %s）", t.text, x.kind(), y.kind())
+	case x.kind() == kindString && t.text != "==" && t.text != "!=":
+		return nil, p.errorf(t, "字符串只能用 == 或 != 比较")
+	case x.kind() == kindBool:
+		return nil, p.errorf(t, "%s 不能用于布尔值", t.text)
+	}
+	if _, ok := p.accept("==", "!=", "<", "<=", ">", ">="); ok {
+		return nil, p.errorf(t, "比较不能连用，请用 && 连接")
+	}
+	return &binary{op: t.text, x: x, y: y}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.parseArithmetic([]string{"+", "-"}, p.parseProduct)
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.parseArithmetic([]string{"*", "/"}, p.parseUnary)
+}
+
+func (p *parser) parseArithmetic(ops []string, operand func() (node, error)) (node, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.accept(ops...)
+		if !ok {
+			return x, nil
+		}
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if x.kind() != kindNumber || y.kind() != kindNumber {
+			return nil, p.errorf(t, "%s 两侧应为数值", t.text)
+		}
+		x = &binary{op: t.text, x: x, y: y}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t, ok := p.accept("-")
+	if !ok {
+		return p.parsePrimary()
+	}
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if x.kind() != kindNumber {
+		return nil, p.errorf(t, "- 只能用于数值")
+	}
+	return &unary{op: "-", x: x}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "无效的数字 %q", t.text)
+		}
+		return &numberLit{v: v}, nil
+
+	case tokDuration:
+		return nil, p.errorf(t, "无效的数字 %q（时长只能用在 for 之后）", t.text)
+
+	case tokString:
+		return &stringLit{v: t.text}, nil
+
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &boolLit{v: t.text == "true"}, nil
+		case "tag":
+			if _, ok := p.accept("."); !ok {
+				return nil, p.errorf(p.peek(), "tag 后应为 .标签名，如 tag.role")
+			}
+			key := p.next()
+			if key.kind != tokIdent {
+				return nil, p.errorf(key, "tag. 后应为标签名")
+			}
+			return &tagRef{key: key.text}, nil
+		}
+		if _, ok := Variables[t.text]; !ok {
+			return nil, p.errorf(t, "未知的变量 %s，可用: %s、tag.<标签名>", t.text, strings.Join(VariableNames(), "、"))
+		}
+		p.addVar(t.text)
+		return &variable{name: t.text}, nil
+
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, p.errorf(p.peek(), "缺少 )")
+			}
+			return x, nil
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, p.errorf(t, "表达式不完整")
+	}
+	return nil, p.errorf(t, "此处不能出现 %q", t.text)
+}
+
+func (p *parser) addVar(name string) {
+	for _, v := range p.vars {
+		if v == name {
+			return
+		}
+	}
+	p.vars = append(p.vars, name)
+}
@@ -0,0 +1,155 @@
+// Package rules 实现告警规则表达式：对节点指标和标签求值的布尔表达式，可带 for 持续时间
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Variables 表达式可用的指标变量及说明，取值由调用方通过 Env 提供
+var Variables = map[string]string{
+	"cpu":                      "CPU使用率（%），按 thresholds.cpu_aggregate 取区间统计值",
+	"mem":                      "内存使用率（%）",
+	"mem_used":                 "已用内存（字节）",
+	"mem_total":                "总内存（字节）",
+	"net_in_mbps":              "入站带宽（Mbps），按 thresholds.bandwidth_aggregate 取区间统计值",
+	"net_out_mbps":             "出站带宽（Mbps），按 thresholds.bandwidth_aggregate 取区间统计值",
+	"bandwidth_mbps":           "入站与出站带宽的较小值（Mbps）",
+	"bandwidth_threshold_mbps": "节点带宽阈值（Mbps），客户端未上报时为 thresholds.bandwidth_mbps",
+	"uptime":                   "运行时长（秒）",
+	"clock_skew":               "时钟偏差（秒），客户端偏快为正",
+	"speed_down_mbps":          "最近一次测速的下载速率（Mbps）",
+	"speed_up_mbps":            "最近一次测速的上传速率（Mbps）",
+	"speed_mbps":               "最近一次测速上传与下载速率的较小值（Mbps）",
+	"hostname_nodes":           "使用相同主机名的在线节点数（含自身）",
+}
+
+// VariableNames 按名称排序的变量列表
+func VariableNames() []string {
+	names := make([]string, 0, len(Variables))
+	for name := range Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Env 表达式求值时的变量来源
+type Env interface {
+	// Number 返回指标变量的值，当前不可用时（如网卡计数重建、尚无测速结果）返回false
+	Number(name string) (float64, bool)
+	// Tag 返回节点标签，不存在时为空字符串
+	Tag(key string) string
+}
+
+// Rule 解析后的告警规则表达式
+type Rule struct {
+	source  string
+	root    node
+	primary *binary // 首个数值比较，其两侧的值作为告警的当前值和阈值
+	vars    []string
+	For     time.Duration // 条件需持续满足的时长，0表示立即告警
+}
+
+// Result 一次求值的结果
+type Result struct {
+	Matched   bool
+	Value     float64 // 首个数值比较左侧的值，没有数值比较时为0
+	Threshold float64 // 首个数值比较右侧的值
+}
+
+// Parse 解析规则表达式，如 `cpu > 90 && net_in_mbps < 10 for 5m`
+func Parse(source string) (*Rule, error) {
+	p := &parser{}
+	if err := p.lex(source); err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokEOF {
+		return nil, fmt.Errorf("表达式为空")
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if root.kind() != kindBool {
+		return nil, fmt.Errorf("表达式结果应为布尔值，实际为%s", root.kind())
+	}
+
+	rule := &Rule{source: strings.TrimSpace(source), root: root, vars: p.vars}
+	if t := p.peek(); t.kind == tokIdent && t.text == "for" {
+		p.next()
+		d := p.next()
+		if d.kind != tokDuration && d.kind != tokNumber {
+			return nil, p.errorf(d, "for 后应为持续时间，如 5m、30s")
+		}
+		if rule.For, err = time.ParseDuration(d.text); err != nil || rule.For <= 0 {
+			return nil, p.errorf(d, "无效的持续时间 %q，应为 5m、30s、1h 这样的正数时长", d.text)
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "多余的内容 %q", t.text)
+	}
+
+	rule.primary = firstComparison(root)
+	return rule, nil
+}
+
+// String 返回规则的原始表达式
+func (r *Rule) String() string {
+	return r.source
+}
+
+// Vars 表达式引用的指标变量，按首次出现的顺序
+func (r *Rule) Vars() []string {
+	return r.vars
+}
+
+// Below 首个数值比较是否为 < 或 <=，即取值越低越严重
+func (r *Rule) Below() bool {
+	return r.primary != nil && (r.primary.op == "<" || r.primary.op == "<=")
+}
+
+// Eval 求值，引用的变量当前不可用时返回false，调用方应保持原有告警状态
+func (r *Rule) Eval(env Env) (Result, bool) {
+	v, ok := r.root.eval(env)
+	if !ok {
+		return Result{}, false
+	}
+	result := Result{Matched: v.b}
+	if r.primary != nil {
+		left, lok := r.primary.x.eval(env)
+		right, rok := r.primary.y.eval(env)
+		if lok && rok {
+			result.Value, result.Threshold = left.num, right.num
+		}
+	}
+	return result, true
+}
+
+// firstComparison 按从左到右的顺序查找第一个数值比较
+func firstComparison(n node) *binary {
+	switch n := n.(type) {
+	case *binary:
+		if isComparison(n.op) && n.x.kind() == kindNumber {
+			return n
+		}
+		if c := firstComparison(n.x); c != nil {
+			return c
+		}
+		return firstComparison(n.y)
+	case *unary:
+		return firstComparison(n.x)
+	}
+	return nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
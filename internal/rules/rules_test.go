@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEnv 测试用的变量来源，未列出的变量视为不可用
+type testEnv struct {
+	numbers map[string]float64
+	tags    map[string]string
+}
+
+func (e testEnv) Number(name string) (float64, bool) {
+	v, ok := e.numbers[name]
+	return v, ok
+}
+
+func (e testEnv) Tag(key string) string {
+	return e.tags[key]
+}
+
+func TestEval(t *testing.T) {
+	env := testEnv{
+		numbers: map[string]float64{"cpu": 95, "mem": 40, "mem_used": 3, "mem_total": 4, "net_in_mbps": 5, "uptime": 0},
+		tags:    map[string]string{"role": "db"},
+	}
+
+	tests := []struct {
+		expr      string
+		matched   bool
+		value     float64
+		threshold float64
+	}{
+		// 优先级：乘除高于加减，比较高于 !，&& 高于 ||
+		{"cpu - 5 * 2 > 84", true, 85, 84},
+		{"(cpu - 5) * 2 > 84", true, 180, 84},
+		{"cpu > 90 || mem > 90 && net_in_mbps > 10", true, 95, 90},
+		{"(cpu > 90 || mem > 90) && net_in_mbps > 10", false, 95, 90},
+		{"!cpu > 90 || mem < 50", true, 95, 90},
+		{"!(cpu > 90 && mem < 50)", false, 95, 90},
+		{"-cpu < -90", true, -95, -90},
+		{"mem_used / mem_total > 0.7", true, 0.75, 0.7},
+		{"cpu - 90 - 5 == 0", true, 0, 0}, // 左结合
+		{"cpu / 5 / 19 == 1", true, 1, 1},
+
+		// 指数形式的数字
+		{"net_in_mbps * 1e6 > 4.5e6", true, 5e6, 4.5e6},
+		{"cpu > 9.5E+1", false, 95, 95},
+		{"mem_used / mem_total > 7.5e-1", false, 0.75, 0.75},
+
+		// 标签
+		{"tag.role == 'db'", true, 0, 0},
+		{`tag.role != "db" || cpu > 99`, false, 95, 99},
+		{"tag.missing == ''", true, 0, 0},
+		{"cpu > 90 && tag.role == 'web'", false, 95, 90},
+
+		// 除零得到 NaN，与 NaN 的比较均不成立
+		{"cpu / uptime > 1", false, math.NaN(), 1},
+		{"cpu / uptime < 1", false, math.NaN(), 1},
+		{"!(cpu / uptime > 1)", true, math.NaN(), 1},
+
+		// 首个数值比较决定告警的当前值和阈值
+		{"tag.role == 'db' && mem > 30", true, 40, 30},
+		{"true", true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			rule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			result, ok := rule.Eval(env)
+			if !ok {
+				t.Fatal("求值返回不可用")
+			}
+			if result.Matched != tt.matched {
+				t.Errorf("Matched = %v，期望 %v", result.Matched, tt.matched)
+			}
+			if !sameFloat(result.Value, tt.value) || result.Threshold != tt.threshold {
+				t.Errorf("Value/Threshold = %v/%v，期望 %v/%v", result.Value, result.Threshold, tt.value, tt.threshold)
+			}
+		})
+	}
+}
+
+func sameFloat(a, b float64) bool {
+	return a == b || (math.IsNaN(a) && math.IsNaN(b))
+}
+
+func TestEvalUnavailable(t *testing.T) {
+	env := testEnv{numbers: map[string]float64{"cpu": 50}}
+
+	tests := []struct {
+		expr    string
+		ok      bool
+		matched bool
+	}{
+		{"speed_mbps < 100", false, false},
+		{"cpu > 90 && speed_mbps < 100", true, false}, // 短路，未求值一侧不影响结果
+		{"cpu < 90 || speed_mbps < 100", true, true},
+		{"cpu < 90 && speed_mbps < 100", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			rule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			result, ok := rule.Eval(env)
+			if ok != tt.ok || result.Matched != tt.matched {
+				t.Errorf("Eval = %v, %v，期望 %v, %v", result.Matched, ok, tt.matched, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseFor(t *testing.T) {
+	tests := []struct {
+		expr string
+		want time.Duration
+	}{
+		{"cpu > 90", 0},
+		{"cpu > 90 for 5m", 5 * time.Minute},
+		{"cpu > 90 for 30s", 30 * time.Second},
+		{"cpu > 90 for 1h30m", 90 * time.Minute},
+		{"(cpu > 90 && mem > 90) for 1.5m", 90 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			rule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if rule.For != tt.want {
+				t.Errorf("For = %v，期望 %v", rule.For, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // 错误信息应包含的内容
+	}{
+		{"", "表达式为空"},
+		{"cpu", "应为布尔值"},
+		{"cpu > ", "表达式末尾: 表达式不完整"},
+		{"cpu > 90 &&", "表达式末尾"},
+		{"cpuu > 90", "第1个字符: 未知的变量 cpuu"},
+		{"cpu > 90 && mem >> 3", "第18个字符"},
+		{"cpu > 90 > 80", "比较不能连用"},
+		{"cpu > 'x'", "两侧类型不同"},
+		{"tag.role > 'a'", "字符串只能用 == 或 != 比较"},
+		{"tag.role == 1", "两侧类型不同"},
+		{"cpu + tag.role > 1", "两侧应为数值"},
+		{"cpu && mem > 1", "两侧应为布尔值"},
+		{"!cpu", "! 只能用于布尔值"},
+		{"(cpu > 90", "缺少 )"},
+		{"cpu > 90)", "多余的内容"},
+		{"tag.", "tag. 后应为标签名"},
+		{"tag == 'x'", "tag 后应为 .标签名"},
+		{"tag.role == 'db", "字符串缺少结束引号"},
+		{"cpu > 5m", "时长只能用在 for 之后"},
+		{"cpu > 1.2.3", "无效的数字"},
+		{"cpu > 1e", "时长只能用在 for 之后"},
+		{"cpu > 1e+", "第7个字符"},
+		{"cpu > 1e6x", "时长只能用在 for 之后"},
+		{"cpu > 90 for", "for 后应为持续时间"},
+		{"cpu > 90 for 5", "无效的持续时间"},
+		{"cpu > 90 for -5m", "for 后应为持续时间"},
+		{"cpu > 90 for 5x", "无效的持续时间"},
+		{"cpu > 90 for 5m extra", "多余的内容"},
+		{"cpu # 90", "第5个字符: 无法识别的字符"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil {
+				t.Fatalf("期望解析失败")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("错误 %q 不包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleMetadata(t *testing.T) {
+	rule, err := Parse("  net_in_mbps < 10 && cpu > 90 && net_in_mbps > 0 for 5m ")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if got := strings.Join(rule.Vars(), ","); got != "net_in_mbps,cpu" {
+		t.Errorf("Vars = %s", got)
+	}
+	if !rule.Below() {
+		t.Error("首个比较为 <，Below 应为 true")
+	}
+	if rule.String() != "net_in_mbps < 10 && cpu > 90 && net_in_mbps > 0 for 5m" {
+		t.Errorf("String = %q", rule.String())
+	}
+}
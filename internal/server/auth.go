@@ -0,0 +1,76 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// credentialRole 返回凭证对应的角色；服务端密码由所有客户端共享，只视为reporter，
+// viewer 和 admin 权限须使用 api_keys 中对应角色的密钥。无效凭证返回空字符串
+func (s *Server) credentialRole(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	if subtle.ConstantTimeCompare([]byte(credential), []byte(s.cfg().Password)) == 1 {
+		return models.RoleReporter
+	}
+	for _, key := range s.cfg().APIKeys {
+		if key.Key != "" && subtle.ConstantTimeCompare([]byte(credential), []byte(key.Key)) == 1 {
+			return key.Role
+		}
+	}
+	return ""
+}
+
+// roleAllows admin 拥有全部权限，其余角色只能访问对应的接口
+func roleAllows(role, required string) bool {
+	return role == models.RoleAdmin || (role != "" && role == required)
+}
+
+// authorized 判断凭证是否具备所需角色
+func (s *Server) authorized(credential, required string) bool {
+	return roleAllows(s.credentialRole(credential), required)
+}
+
+// requestCredential 从请求中读取凭证：Authorization: Bearer、X-Password 请求头，或 token 参数（供浏览器 EventSource 使用）
+func requestCredential(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if password := r.Header.Get("X-Password"); password != "" {
+		return password
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authorize 校验请求凭证的角色，失败时返回 401/403 并返回 false
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, required string) bool {
+	role := s.credentialRole(requestCredential(r))
+	if roleAllows(role, required) {
+		return true
+	}
+
+	code, message := http.StatusUnauthorized, s.tr(r, "api.unauthorized")
+	if role != "" {
+		code, message = http.StatusForbidden, s.tr(r, "api.forbidden")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(models.APIResponse{Success: false, Message: message})
+	return false
+}
+
+// reporterSecrets 可用于签名UDP心跳的凭证（服务端密码及 reporter/admin 密钥）
+func (s *Server) reporterSecrets() []string {
+	secrets := []string{s.cfg().Password}
+	for _, key := range s.cfg().APIKeys {
+		if key.Key != "" && roleAllows(key.Role, models.RoleReporter) {
+			secrets = append(secrets, key.Key)
+		}
+	}
+	return secrets
+}
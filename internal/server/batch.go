@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+
+	"bandwidth-monitor/internal/compress"
+	"bandwidth-monitor/internal/models"
+)
+
+// handleReportBatch 批量上报：按节点和上报序号排序后依次处理，丢弃重复和乱序的条目
+func (s *Server) handleReportBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendResponse(w, false, s.tr(r, "api.post_only"), nil)
+		return
+	}
+
+	var batch models.BatchReportRequest
+	if err := compress.DecodeJSONBody(r, &batch); err != nil {
+		s.sendResponse(w, false, s.tr(r, "api.invalid_json_detail", err), nil)
+		return
+	}
+
+	// 验证密码（服务端密码或 reporter 密钥）
+	if !s.authorized(batch.Password, models.RoleReporter) {
+		s.sendResponse(w, false, s.trErr(r, errInvalidPassword), nil)
+		return
+	}
+
+	s.sendResponse(w, true, s.tr(r, "api.batch_report_ok"), s.ingestBatch(batch.Reports))
+}
+
+// ingestBatch 处理一批已认证的上报
+func (s *Server) ingestBatch(reports []models.ReportRequest) *models.BatchReportResponse {
+	// 串行处理批量上报，保证去重判断与状态更新之间不被其他批次插入
+	s.batchMutex.Lock()
+	defer s.batchMutex.Unlock()
+
+	// 稳定排序保证同一节点按上报顺序进入状态更新流程
+	sort.SliceStable(reports, func(i, j int) bool {
+		a, b := &reports[i], &reports[j]
+		if a.NodeKey() != b.NodeKey() {
+			return a.NodeKey() < b.NodeKey()
+		}
+		// 同一进程的上报按序号排序，不受客户端时钟调整影响
+		if a.Seq > 0 && b.Seq > 0 && a.Session == b.Session {
+			return a.Seq < b.Seq
+		}
+		return a.Timestamp < b.Timestamp
+	})
+
+	result := &models.BatchReportResponse{Responses: make(map[string]*models.ReportResponse)}
+	newest := make(map[string]*models.ReportRequest)
+	for i := range reports {
+		req := &reports[i]
+		if req.Hostname == "" {
+			continue
+		}
+
+		if s.isDuplicateReport(req) {
+			result.Duplicates++
+			continue
+		}
+
+		resp := s.updateNodeStatus(req)
+		result.Accepted++
+
+		// 合并同一节点的多条响应：一次性指令取并集，其余取最新
+		key := req.NodeKey()
+		if merged, ok := result.Responses[key]; ok {
+			resp.SpeedTestRequested = resp.SpeedTestRequested || merged.SpeedTestRequested
+			if resp.Config == nil {
+				resp.Config = merged.Config
+			}
+		}
+		result.Responses[key] = resp
+		newest[key] = req
+	}
+
+	// 每个节点最新的一条是刚采集即发送的，用于估算时钟偏差
+	for key, req := range newest {
+		s.recordClockSkew(req, result.Responses[key])
+	}
+
+	return result
+}
+
+// isDuplicateReport 判断上报是否已处理过：同一 session 内序号不大于已处理的最新序号；
+// 客户端重启后 session 改变，序号重新计数。不带序号的旧版客户端按时间戳判断
+func (s *Server) isDuplicateReport(req *models.ReportRequest) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	node, exists := s.nodes[req.NodeKey()]
+	if !exists {
+		return false
+	}
+	if req.Seq > 0 {
+		return req.Session == node.LastReportSession && req.Seq <= node.LastReportSeq
+	}
+	return req.Timestamp > 0 && req.Timestamp <= node.LastReportTimestamp
+}
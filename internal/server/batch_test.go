@@ -0,0 +1,55 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	config := &models.ServerConfig{
+		Password:   "pw",
+		Thresholds: models.Threshold{BandwidthMbps: 0, OfflineSeconds: 300, ClockSkewSeconds: 30},
+	}
+	s, err := NewServer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.incidents, err = loadIncidentStore(filepath.Join(t.TempDir(), "incidents.jsonl"), 90); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.incidents.flush() })
+	return s
+}
+
+func TestIngestBatchDeduplicatesBySeq(t *testing.T) {
+	s := newTestServer(t)
+	report := func(session string, seq, timestamp int64) models.ReportRequest {
+		return models.ReportRequest{NodeID: "n1", Hostname: "h1", Session: session, Seq: seq, Timestamp: timestamp}
+	}
+
+	tests := []struct {
+		name       string
+		reports    []models.ReportRequest
+		accepted   int
+		duplicates int
+	}{
+		{"首批乱序", []models.ReportRequest{report("a", 2, 1002), report("a", 1, 1001)}, 2, 0},
+		{"重发已处理的上报", []models.ReportRequest{report("a", 2, 1002), report("a", 3, 1003)}, 1, 1},
+		{"时钟回拨后的新上报", []models.ReportRequest{report("a", 4, 500), report("a", 5, 501)}, 2, 0},
+		{"客户端重启后序号重新计数", []models.ReportRequest{report("b", 1, 502)}, 1, 0},
+		{"旧 session 的重发", []models.ReportRequest{report("b", 1, 502)}, 0, 1},
+	}
+	for _, tt := range tests {
+		result := s.ingestBatch(tt.reports)
+		if result.Accepted != tt.accepted || result.Duplicates != tt.duplicates {
+			t.Errorf("%s: accepted/duplicates = %d/%d，期望 %d/%d", tt.name, result.Accepted, result.Duplicates, tt.accepted, tt.duplicates)
+		}
+	}
+
+	if node := s.nodes["n1"]; node.LastReportSession != "b" || node.LastReportSeq != 1 {
+		t.Errorf("最新序号 = %s/%d", node.LastReportSession, node.LastReportSeq)
+	}
+}
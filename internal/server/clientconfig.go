@@ -0,0 +1,33 @@
+package server
+
+import (
+	"path"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// desiredClientConfig 返回节点适用的集中配置，按配置顺序取第一条匹配的规则
+func (s *Server) desiredClientConfig(hostname string) *models.PushedConfig {
+	for _, rule := range s.cfg().ClientConfigs {
+		if matchHost(hostname, rule.Hosts) {
+			return &models.PushedConfig{
+				Version: rule.Config.Version(),
+				Config:  rule.Config,
+			}
+		}
+	}
+	return nil
+}
+
+// matchHost 判断主机名是否匹配任一glob规则，规则为空表示匹配全部
+func matchHost(hostname string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, hostname); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// recordClockSkew 根据上报时间戳计算客户端时钟偏差，并在响应中返回服务端时间。
+// 仅用于客户端刚采集即发送的上报；经中继或批量缓存的上报本身有延迟，不能用来估算偏差。
+func (s *Server) recordClockSkew(req *models.ReportRequest, resp *models.ReportResponse) {
+	if req.Timestamp <= 0 || req.Via != "" {
+		return
+	}
+
+	now := time.Now()
+	resp.ServerTime = now.Unix()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	node, ok := s.nodes[req.NodeKey()]
+	if !ok {
+		return
+	}
+
+	node.ClockSkewSeconds = req.Timestamp - now.Unix()
+	s.evaluateAlertRules(node, "clock_skew")
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// 内存中保留的事件条数，用于 Last-Event-ID 断线续传
+const eventLogSize = 1000
+
+// eventBus 保存最近的事件并分发给SSE订阅者
+type eventBus struct {
+	mutex       sync.Mutex
+	nextID      int64
+	log         []models.Event
+	subscribers map[chan models.Event]struct{}
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		nextID:      1,
+		subscribers: make(map[chan models.Event]struct{}),
+	}
+}
+
+// publish 为事件分配ID、写入环形日志并分发。订阅者处理不及时（缓冲已满）时关闭其通道，
+// 由客户端重连后按 Last-Event-ID 从日志补齐，而不是静默丢弃事件
+func (b *eventBus) publish(event models.Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	event.ID = b.nextID
+	b.nextID++
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.log = append(b.log, event)
+	if len(b.log) > eventLogSize {
+		b.log = b.log[len(b.log)-eventLogSize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// eventGap 续传位置之后的部分事件已不在日志中，客户端需通过 /api/status 重新同步
+type eventGap struct {
+	LastEventID  int64 `json:"last_event_id"`  // 客户端请求的续传位置
+	FirstEventID int64 `json:"first_event_id"` // 日志中最早的事件，续传从此开始
+}
+
+// subscribe 返回 afterID 之后仍在日志中的事件以及后续事件的通道；
+// afterID 之后的事件已被日志淘汰（或 afterID 来自重启前的服务端）时同时返回缺口信息。
+// 通道在订阅者处理不及时时被关闭
+func (b *eventBus) subscribe(afterID int64) ([]models.Event, *eventGap, chan models.Event, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var backlog []models.Event
+	for _, e := range b.log {
+		if e.ID > afterID {
+			backlog = append(backlog, e)
+		}
+	}
+
+	var gap *eventGap
+	switch {
+	case afterID >= b.nextID:
+		// 服务端重启后事件ID从1开始，此前的续传位置已失效，从日志开头补齐
+		backlog = append([]models.Event{}, b.log...)
+		gap = &eventGap{LastEventID: afterID, FirstEventID: b.nextID}
+	case len(b.log) > 0 && b.log[0].ID > afterID+1:
+		gap = &eventGap{LastEventID: afterID}
+	}
+	if gap != nil && len(b.log) > 0 {
+		gap.FirstEventID = b.log[0].ID
+	}
+
+	ch := make(chan models.Event, 64)
+	b.subscribers[ch] = struct{}{}
+
+	return backlog, gap, ch, func() {
+		b.mutex.Lock()
+		delete(b.subscribers, ch)
+		b.mutex.Unlock()
+	}
+}
+
+// emitNodeEvent 发布带节点完整状态的事件（调用方需持有 s.mutex）
+func (s *Server) emitNodeEvent(eventType string, node *models.NodeStatus, alert string, value, threshold float64) {
+	s.events.publish(nodeEvent(eventType, node, alert, value, threshold))
+}
+
+// nodeEvent 生成带节点快照的事件，供需要补充字段的调用方使用
+func nodeEvent(eventType string, node *models.NodeStatus, alert string, value, threshold float64) models.Event {
+	snapshot := *node
+	return models.Event{
+		Type:      eventType,
+		Hostname:  node.Hostname,
+		Alert:     alert,
+		Value:     value,
+		Threshold: threshold,
+		Node:      &snapshot,
+	}
+}
+
+// eventFilter SSE 订阅的过滤条件
+type eventFilter struct {
+	hostnames map[string]bool
+	types     map[string]bool
+}
+
+func parseListParam(value string) map[string]bool {
+	if value == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
+
+func (f eventFilter) match(e models.Event) bool {
+	if f.types != nil && !f.types[e.Type] {
+		return false
+	}
+	// 未关联节点的事件（如静默规则）只按类型过滤
+	if f.hostnames != nil && e.Hostname != "" && !f.hostnames[e.Hostname] {
+		return false
+	}
+	return true
+}
+
+// handleEvents 以SSE推送状态变化事件，支持 Last-Event-ID 续传和 hostname/type 过滤
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.sendResponse(w, false, s.tr(r, "api.get_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleViewer) {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.sendResponse(w, false, s.tr(r, "api.streaming_unsupported"), nil)
+		return
+	}
+
+	filter := eventFilter{
+		hostnames: parseListParam(r.URL.Query().Get("hostname")),
+		types:     parseListParam(r.URL.Query().Get("type")),
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id") // 浏览器 EventSource 之外的客户端也可用参数指定
+	}
+	afterID, _ := strconv.ParseInt(lastID, 10, 64)
+
+	// 未指定续传位置时只推送新事件
+	backlog, gap, events, unsubscribe := s.events.subscribe(afterID)
+	defer unsubscribe()
+	if lastID == "" {
+		backlog, gap = nil, nil
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if gap != nil {
+		writeGap(w, gap)
+	}
+	for _, e := range backlog {
+		if filter.match(e) {
+			writeSSE(w, e)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				// 处理不及时被断开，客户端重连后按 Last-Event-ID 续传
+				notifyLog.Warn("SSE订阅者处理不及时，已断开", "remote", r.RemoteAddr)
+				return
+			}
+			if !filter.match(e) {
+				continue
+			}
+			writeSSE(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, e models.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
+
+// writeGap 发送不带ID的 gap 事件，不影响客户端记录的 Last-Event-ID
+func writeGap(w http.ResponseWriter, gap *eventGap) {
+	data, err := json.Marshal(gap)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: gap\ndata: %s\n\n", data)
+}
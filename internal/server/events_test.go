@@ -0,0 +1,70 @@
+package server
+
+import (
+	"testing"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func TestEventBusClosesSlowSubscriber(t *testing.T) {
+	bus := newEventBus()
+	_, _, ch, unsubscribe := bus.subscribe(0)
+	defer unsubscribe()
+
+	for i := 0; i < cap(ch)+1; i++ {
+		bus.publish(models.Event{Type: models.EventNodeNew})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	if received != cap(ch) {
+		t.Fatalf("收到 %d 条事件，期望 %d 条后通道关闭", received, cap(ch))
+	}
+
+	// 重连后从最后收到的ID续传，不遗漏溢出的事件
+	backlog, gap, _, unsubscribe2 := bus.subscribe(int64(received))
+	defer unsubscribe2()
+	if gap != nil {
+		t.Fatalf("日志中仍有全部事件，不应返回缺口: %+v", gap)
+	}
+	if len(backlog) != 1 || backlog[0].ID != int64(received+1) {
+		t.Fatalf("续传事件 = %+v，期望ID为 %d 的一条", backlog, received+1)
+	}
+}
+
+func TestEventBusGap(t *testing.T) {
+	bus := newEventBus()
+	for i := 0; i < eventLogSize+10; i++ {
+		bus.publish(models.Event{Type: models.EventNodeNew})
+	}
+
+	tests := []struct {
+		name      string
+		afterID   int64
+		wantGap   bool
+		wantFirst int64
+		wantCount int
+	}{
+		{"仍在日志中", 500, false, 0, eventLogSize + 10 - 500},
+		{"恰好是淘汰的最后一条", 10, false, 0, eventLogSize},
+		{"已被淘汰", 5, true, 11, eventLogSize},
+		{"重启前的ID", 5000, true, 11, eventLogSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, gap, _, unsubscribe := bus.subscribe(tt.afterID)
+			defer unsubscribe()
+			if (gap != nil) != tt.wantGap {
+				t.Fatalf("gap = %+v，期望 %v", gap, tt.wantGap)
+			}
+			if gap != nil && (gap.LastEventID != tt.afterID || gap.FirstEventID != tt.wantFirst) {
+				t.Errorf("gap = %+v，期望 first_event_id %d", gap, tt.wantFirst)
+			}
+			if len(backlog) != tt.wantCount {
+				t.Errorf("续传 %d 条，期望 %d 条", len(backlog), tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService 实现 rpc.MonitorServer，与HTTP接口共用认证和状态更新流程
+type grpcService struct {
+	s *Server
+}
+
+func (s *Server) startGRPC() error {
+	lis, err := net.Listen("tcp", s.cfg().GRPCListen)
+	if err != nil {
+		return err
+	}
+
+	var opts []grpc.ServerOption
+	if files := s.cfg().GRPCTLS; files.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(files.CertFile, files.KeyFile)
+		if err != nil {
+			lis.Close()
+			return fmt.Errorf("加载gRPC证书失败: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	s.grpcServer = grpc.NewServer(opts...)
+	rpc.RegisterMonitorServer(s.grpcServer, &grpcService{s: s})
+
+	go func() {
+		ingestLog.Info("gRPC服务已启动", "listen", s.cfg().GRPCListen, "tls", len(opts) > 0)
+		if err := s.grpcServer.Serve(lis); err != nil {
+			ingestLog.Error("gRPC服务退出", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Report 逐条处理客户端流上的上报并返回响应
+func (g *grpcService) Report(stream rpc.Monitor_ReportServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := g.s.ingestReport(req)
+		if errors.Is(err, errInvalidPassword) {
+			return status.Error(codes.Unauthenticated, i18n.Localize(g.s.defaultLang(), err))
+		}
+		if err != nil {
+			return status.Error(codes.InvalidArgument, i18n.Localize(g.s.defaultLang(), err))
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// WatchStatus 推送当前节点状态快照，之后持续推送状态变化
+func (g *grpcService) WatchStatus(req *rpc.WatchRequest, stream rpc.Monitor_WatchStatusServer) error {
+	if !g.s.authorized(req.Password, models.RoleViewer) {
+		return status.Error(codes.Unauthenticated, i18n.Localize(g.s.defaultLang(), errInvalidPassword))
+	}
+
+	// 先订阅再取快照，避免遗漏两者之间的变化
+	updates, unsubscribe := g.s.subscribeStatus()
+	defer unsubscribe()
+
+	for _, node := range g.s.snapshotNodes() {
+		if req.Hostname != "" && node.Hostname != req.Hostname {
+			continue
+		}
+		if err := stream.Send(&node); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case node := <-updates:
+			if req.Hostname != "" && node.Hostname != req.Hostname {
+				continue
+			}
+			if err := stream.Send(&node); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// subscribeStatus 订阅节点状态变化，返回的函数用于取消订阅
+func (s *Server) subscribeStatus() (chan models.NodeStatus, func()) {
+	ch := make(chan models.NodeStatus, 64)
+
+	s.subMutex.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.subMutex.Unlock()
+
+	return ch, func() {
+		s.subMutex.Lock()
+		delete(s.subscribers, ch)
+		s.subMutex.Unlock()
+	}
+}
+
+// publishStatus 向所有订阅者推送节点状态副本，订阅者处理不及时则丢弃
+func (s *Server) publishStatus(node *models.NodeStatus) {
+	s.subMutex.Lock()
+	defer s.subMutex.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- *node:
+		default:
+		}
+	}
+}
+
+// snapshotNodes 返回所有节点状态的副本
+func (s *Server) snapshotNodes() []models.NodeStatus {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	nodes := make([]models.NodeStatus, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		nodes = append(nodes, *node)
+	}
+	return nodes
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"net"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func (s *Server) startHeartbeat() error {
+	conn, err := net.ListenPacket("udp", s.cfg().HeartbeatListen)
+	if err != nil {
+		return err
+	}
+	s.heartbeatConn = conn
+
+	go func() {
+		ingestLog.Info("UDP心跳服务已启动", "listen", s.cfg().HeartbeatListen)
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				ingestLog.Error("UDP心跳服务退出", "error", err)
+				return
+			}
+
+			var hb models.Heartbeat
+			if err := json.Unmarshal(buf[:n], &hb); err != nil {
+				ingestLog.Debug("心跳包解析失败", "addr", addr.String(), "error", err)
+				continue
+			}
+			s.handleHeartbeat(&hb, addr)
+		}
+	}()
+
+	return nil
+}
+
+// handleHeartbeat 校验签名与时间戳后刷新节点心跳时间；仅接受已上报过的节点
+func (s *Server) handleHeartbeat(hb *models.Heartbeat, addr net.Addr) {
+	signed := false
+	for _, secret := range s.reporterSecrets() {
+		expected := models.HeartbeatSignature(secret, hb.NodeID, hb.Hostname, hb.Timestamp)
+		if hmac.Equal([]byte(hb.Signature), []byte(expected)) {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		ingestLog.Warn("心跳签名错误", "addr", addr.String(), "node", hb.Hostname)
+		return
+	}
+
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := hb.NodeID
+	if key == "" {
+		key = hb.Hostname
+	}
+	node, ok := s.nodes[key]
+	if !ok {
+		return
+	}
+
+	// 时间戳必须递增，防止截获的心跳包被重放；没有上一次心跳时（首个心跳或服务端重启后）
+	// 按节点测得的时钟偏差校正后检查时间窗口，时钟不准的节点同样可以按心跳判断在线
+	if last, seen := s.heartbeatSeq[key]; seen {
+		if hb.Timestamp <= last {
+			return
+		}
+	} else if !heartbeatInWindow(hb, node, now, s.cfg().HeartbeatMaxSkewSeconds) {
+		ingestLog.Warn("心跳时间戳超出允许范围", "addr", addr.String(), "node", hb.Hostname,
+			"clock_skew_seconds", node.ClockSkewSeconds)
+		return
+	}
+	s.heartbeatSeq[key] = hb.Timestamp
+
+	if node.LastHeartbeat.IsZero() {
+		ingestLog.Info("节点开始发送UDP心跳", "node", hb.Hostname)
+	}
+	node.LastHeartbeat = now
+}
+
+// heartbeatInWindow 心跳时间戳扣除节点时钟偏差后与服务端时间的差不超过 maxSkew 秒（负数不检查）
+func heartbeatInWindow(hb *models.Heartbeat, node *models.NodeStatus, now time.Time, maxSkew int) bool {
+	if maxSkew < 0 {
+		return true
+	}
+	sent := time.Unix(0, hb.Timestamp).Add(-time.Duration(node.ClockSkewSeconds) * time.Second)
+	diff := sent.Sub(now)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= time.Duration(maxSkew)*time.Second
+}
+
+// heartbeatLost 节点发送过心跳且心跳已超时（须持有 s.mutex）
+func (s *Server) heartbeatLost(node *models.NodeStatus, now time.Time) bool {
+	if s.heartbeatConn == nil || node.LastHeartbeat.IsZero() {
+		return false
+	}
+	timeout := time.Duration(s.cfg().Thresholds.HeartbeatOfflineSeconds) * time.Second
+	if timeout < 0 {
+		return false
+	}
+	return now.Sub(node.LastHeartbeat) > timeout && now.Sub(node.LastSeen) > timeout
+}
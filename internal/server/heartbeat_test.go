@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func TestHandleHeartbeat(t *testing.T) {
+	s := newTestServer(t)
+	s.cfg().HeartbeatMaxSkewSeconds = 60
+	// 节点时钟偏快5分钟，超出心跳时间窗口但已由上报测得
+	s.nodes["n1"] = &models.NodeStatus{NodeID: "n1", Hostname: "h1", ClockSkewSeconds: 300}
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	send := func(sent time.Time) bool {
+		hb := &models.Heartbeat{NodeID: "n1", Hostname: "h1", Timestamp: sent.UnixNano()}
+		hb.Signature = models.HeartbeatSignature("pw", hb.NodeID, hb.Hostname, hb.Timestamp)
+		s.nodes["n1"].LastHeartbeat = time.Time{}
+		s.handleHeartbeat(hb, addr)
+		return !s.nodes["n1"].LastHeartbeat.IsZero()
+	}
+
+	now := time.Now()
+	if send(now) {
+		t.Error("首个心跳扣除时钟偏差后相差5分钟，应被拒绝")
+	}
+	first := now.Add(300 * time.Second)
+	if !send(first) {
+		t.Fatal("按节点时钟偏差校正后的首个心跳应被接受")
+	}
+	if send(first) {
+		t.Error("重放的心跳应被拒绝")
+	}
+	if send(first.Add(-time.Second)) {
+		t.Error("时间戳回退的心跳应被拒绝")
+	}
+	// 已有上一次心跳后只要求时间戳递增，不再比较时间窗口
+	if !send(first.Add(10 * time.Minute)) {
+		t.Error("时间戳递增的心跳应被接受")
+	}
+}
+
+func TestHeartbeatLostDisabled(t *testing.T) {
+	s := newTestServer(t)
+	s.heartbeatConn = &net.UDPConn{}
+	now := time.Now()
+	node := &models.NodeStatus{LastSeen: now.Add(-time.Hour), LastHeartbeat: now.Add(-time.Hour)}
+
+	s.cfg().Thresholds.HeartbeatOfflineSeconds = 15
+	if !s.heartbeatLost(node, now) {
+		t.Error("心跳超时应判定为丢失")
+	}
+	s.cfg().Thresholds.HeartbeatOfflineSeconds = -1
+	if s.heartbeatLost(node, now) {
+		t.Error("heartbeat_offline_seconds 为负数时不应按心跳判断离线")
+	}
+}
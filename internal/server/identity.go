@@ -0,0 +1,55 @@
+package server
+
+import (
+	"sort"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// hostnameNodes 与节点使用相同主机名的在线节点，含自身（调用方须持有 s.mutex）。
+// 多个节点标识上报同一主机名通常是克隆的机器或重装后的节点，由内置规则 hostname_conflict 告警。
+func hostnameNodes(nodes map[string]*models.NodeStatus, node *models.NodeStatus) []*models.NodeStatus {
+	same := []*models.NodeStatus{node}
+	for _, other := range nodes {
+		if other != node && other.IsOnline && other.Hostname == node.Hostname {
+			same = append(same, other)
+		}
+	}
+	return same
+}
+
+// hostnameNodeIDs 使用相同主机名的节点标识，附在主机名冲突通知中
+func hostnameNodeIDs(nodes map[string]*models.NodeStatus, node *models.NodeStatus) []string {
+	var ids []string
+	for _, n := range hostnameNodes(nodes, node) {
+		ids = append(ids, nodeIDLabel(n))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func nodeIDLabel(node *models.NodeStatus) string {
+	if node.NodeID == "" {
+		return "(旧版客户端)"
+	}
+	return node.NodeID
+}
+
+// nodesByHostname 以主机名为键的节点状态（/api/status 的默认格式）。多个节点使用同一主机名时，
+// 最近上报的节点使用主机名，其余节点的键为 "主机名#节点标识"（调用方须持有 s.mutex）
+func nodesByHostname(nodes map[string]*models.NodeStatus) map[string]*models.NodeStatus {
+	byHostname := make(map[string]*models.NodeStatus, len(nodes))
+	for _, node := range nodes {
+		if existing, ok := byHostname[node.Hostname]; ok {
+			if node.LastSeen.After(existing.LastSeen) ||
+				(node.LastSeen.Equal(existing.LastSeen) && node.NodeID < existing.NodeID) {
+				byHostname[node.Hostname] = node
+				node = existing
+			}
+			byHostname[node.Hostname+"#"+nodeIDLabel(node)] = node
+			continue
+		}
+		byHostname[node.Hostname] = node
+	}
+	return byHostname
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func TestNodesByHostname(t *testing.T) {
+	now := time.Now()
+	nodes := map[string]*models.NodeStatus{
+		"a":      {NodeID: "a", Hostname: "web-1", LastSeen: now.Add(-time.Minute)},
+		"b":      {NodeID: "b", Hostname: "web-1", LastSeen: now},
+		"c":      {NodeID: "c", Hostname: "db-1", LastSeen: now},
+		"legacy": {Hostname: "legacy", LastSeen: now},
+	}
+
+	got := nodesByHostname(nodes)
+	want := map[string]string{"web-1": "b", "web-1#a": "a", "db-1": "c", "legacy": ""}
+	if len(got) != len(want) {
+		t.Fatalf("得到 %d 个键，期望 %d 个: %v", len(got), len(want), got)
+	}
+	for key, id := range want {
+		if node, ok := got[key]; !ok || node.NodeID != id {
+			t.Errorf("键 %q 对应 %+v，期望节点 %q", key, node, id)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// 自上次整理以来追加写入超过该条数时重写记录文件，清理过期和重复的条目
+const incidentCompactWrites = 1000
+
+// incidentStore 告警记录，每次状态变化以 JSON Lines 追加写入文件，同一ID以最后一行为准。
+// 文件写入由后台 goroutine 按顺序完成，上报处理中只做序列化和入队
+type incidentStore struct {
+	mutex     sync.Mutex
+	path      string
+	retention time.Duration
+	incidents []*models.Incident          // 按开始时间排序
+	open      map[string]*models.Incident // 节点键+告警类型 -> 未结束的记录
+	writes    int
+
+	queueMutex sync.Mutex
+	queue      []incidentWrite
+	wake       chan struct{}
+}
+
+// incidentWrite 一次待执行的文件写入：rewrite 为 true 时用 lines 重写整个文件，否则追加
+type incidentWrite struct {
+	path    string
+	lines   [][]byte
+	rewrite bool
+	done    chan error // 非空时写入完成后通知调用方
+}
+
+func incidentKey(nodeKey, alert string) string {
+	return nodeKey + "|" + alert
+}
+
+// loadIncidentStore 加载告警记录；上次运行时未结束的记录标记为服务端重启结束
+func loadIncidentStore(path string, retentionDays int) (*incidentStore, error) {
+	store := &incidentStore{
+		path:      path,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		open:      make(map[string]*models.Incident),
+		wake:      make(chan struct{}, 1),
+	}
+
+	file, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取告警记录失败: %v", err)
+	}
+	if err == nil {
+		byID := make(map[string]*models.Incident)
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var incident models.Incident
+			if err := json.Unmarshal(scanner.Bytes(), &incident); err != nil || incident.ID == "" {
+				continue // 跳过写入中断产生的残行
+			}
+			if existing, ok := byID[incident.ID]; ok {
+				*existing = incident
+				continue
+			}
+			byID[incident.ID] = &incident
+			store.incidents = append(store.incidents, &incident)
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("读取告警记录失败: %v", err)
+		}
+	}
+
+	now := time.Now()
+	for _, incident := range store.incidents {
+		if incident.EndedAt == nil {
+			incident.EndedAt = &now
+			incident.Duration = now.Sub(incident.StartedAt).Seconds()
+			incident.Resolution = models.ResolutionServerRestart
+		}
+	}
+
+	// 写入 goroutine 启动前直接重写，文件不可写时启动失败
+	if err := writeIncidentFile(path, store.compact(now)); err != nil {
+		return nil, err
+	}
+
+	go store.writeLoop()
+	return store, nil
+}
+
+// compact 丢弃过期记录，返回重写文件所需的全部行（须持有 mutex）
+func (st *incidentStore) compact(now time.Time) [][]byte {
+	sort.SliceStable(st.incidents, func(i, j int) bool {
+		return st.incidents[i].StartedAt.Before(st.incidents[j].StartedAt)
+	})
+
+	kept := st.incidents[:0]
+	for _, incident := range st.incidents {
+		if incident.EndedAt == nil || now.Sub(*incident.EndedAt) <= st.retention {
+			kept = append(kept, incident)
+		}
+	}
+	st.incidents = kept
+
+	lines := make([][]byte, 0, len(st.incidents))
+	for _, incident := range st.incidents {
+		if data, err := json.Marshal(incident); err == nil {
+			lines = append(lines, data)
+		}
+	}
+
+	st.writes = 0
+	return lines
+}
+
+// writeIncidentFile 先写临时文件再改名，避免中断时留下不完整的记录文件
+func writeIncidentFile(path string, lines [][]byte) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("写入告警记录失败: %v", err)
+	}
+	writer := bufio.NewWriter(file)
+	for _, line := range lines {
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("写入告警记录失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入告警记录失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入告警记录失败: %v", err)
+	}
+	return nil
+}
+
+// appendIncidentFile 追加若干行记录
+func appendIncidentFile(path string, lines [][]byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("写入告警记录失败: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(bytes.Join(lines, []byte{'\n'}), '\n')); err != nil {
+		return fmt.Errorf("写入告警记录失败: %v", err)
+	}
+	return nil
+}
+
+// enqueue 把写入交给后台 goroutine；重写包含全部记录，此前尚未执行的写入不再需要
+func (st *incidentStore) enqueue(write incidentWrite) {
+	st.queueMutex.Lock()
+	if write.rewrite {
+		for _, pending := range st.queue {
+			if pending.done != nil {
+				pending.done <- nil
+			}
+		}
+		st.queue = st.queue[:0]
+	}
+	st.queue = append(st.queue, write)
+	st.queueMutex.Unlock()
+
+	select {
+	case st.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop 按入队顺序执行文件写入，连续追加到同一文件的记录合并为一次写入
+func (st *incidentStore) writeLoop() {
+	for range st.wake {
+		st.queueMutex.Lock()
+		queue := st.queue
+		st.queue = nil
+		st.queueMutex.Unlock()
+
+		for i := 0; i < len(queue); i++ {
+			write := queue[i]
+			var err error
+			if write.rewrite {
+				err = writeIncidentFile(write.path, write.lines)
+			} else {
+				lines := write.lines
+				for i+1 < len(queue) && !queue[i+1].rewrite && queue[i+1].path == write.path && write.done == nil {
+					i++
+					write = queue[i]
+					lines = append(lines, write.lines...)
+				}
+				if len(lines) > 0 {
+					err = appendIncidentFile(write.path, lines)
+				}
+			}
+			if err != nil {
+				alertLog.Error("写入告警记录失败", "path", write.path, "error", err)
+			}
+			if write.done != nil {
+				write.done <- err
+			}
+		}
+	}
+}
+
+// flush 等待此前入队的写入全部完成
+func (st *incidentStore) flush() error {
+	done := make(chan error, 1)
+	st.enqueue(incidentWrite{done: done})
+	return <-done
+}
+
+// configure 应用重载后的文件路径和保留天数，路径变化时把现有记录写入新文件
+func (st *incidentStore) configure(path string, retentionDays int) error {
+	st.mutex.Lock()
+	st.path = path
+	st.retention = time.Duration(retentionDays) * 24 * time.Hour
+	done := make(chan error, 1)
+	st.enqueue(incidentWrite{path: path, lines: st.compact(time.Now()), rewrite: true, done: done})
+	st.mutex.Unlock()
+
+	return <-done
+}
+
+// persist 将一条记录的当前状态排入追加写入队列（须持有 mutex）
+func (st *incidentStore) persist(incident *models.Incident) {
+	st.writes++
+	if st.writes >= incidentCompactWrites {
+		st.enqueue(incidentWrite{path: st.path, lines: st.compact(time.Now()), rewrite: true})
+		return
+	}
+
+	data, err := json.Marshal(incident)
+	if err != nil {
+		return
+	}
+	st.enqueue(incidentWrite{path: st.path, lines: [][]byte{data}})
+}
+
+// start 记录告警开始，已有未结束的同类记录时忽略
+func (st *incidentStore) start(nodeKey string, node *models.NodeStatus, alert string, startedAt time.Time, value, threshold float64) {
+	st.add(nodeKey, &models.Incident{
+		NodeID:     node.NodeID,
+		Hostname:   node.Hostname,
+		Alert:      alert,
+		StartedAt:  startedAt,
+		Threshold:  threshold,
+		StartValue: value,
+		PeakValue:  value,
+	})
+}
+
+// startRule 记录规则告警开始，附带规则的级别和标签
+func (st *incidentStore) startRule(nodeKey string, node *models.NodeStatus, rule *models.AlertRule, startedAt time.Time, value, threshold float64) {
+	st.add(nodeKey, &models.Incident{
+		NodeID:     node.NodeID,
+		Hostname:   node.Hostname,
+		Alert:      rule.Name,
+		StartedAt:  startedAt,
+		Threshold:  threshold,
+		StartValue: value,
+		PeakValue:  value,
+		Severity:   rule.Severity,
+		Labels:     rule.Labels,
+	})
+}
+
+func (st *incidentStore) add(nodeKey string, incident *models.Incident) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	key := incidentKey(nodeKey, incident.Alert)
+	if _, ok := st.open[key]; ok {
+		return
+	}
+
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+	incident.ID = hex.EncodeToString(idBytes)
+
+	st.open[key] = incident
+	st.incidents = append(st.incidents, incident)
+	st.persist(incident)
+}
+
+// observeRule 规则告警持续期间更新最严重的值，只保存在内存中，结束时一并写入。
+// below 表示低于阈值触发，取最小值；时钟偏差有正负两个方向，取绝对值最大的
+func (st *incidentStore) observeRule(nodeKey, alert string, value float64, below bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	incident, ok := st.open[incidentKey(nodeKey, alert)]
+	if !ok {
+		return
+	}
+	switch {
+	case alert == models.AlertClockSkew:
+		if math.Abs(value) > math.Abs(incident.PeakValue) {
+			incident.PeakValue = value
+		}
+	case below:
+		incident.PeakValue = math.Min(incident.PeakValue, value)
+	default:
+		incident.PeakValue = math.Max(incident.PeakValue, value)
+	}
+}
+
+// end 结束告警记录并返回持续时长，没有对应记录时返回0
+func (st *incidentStore) end(nodeKey, alert string, value float64, resolution string) time.Duration {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	key := incidentKey(nodeKey, alert)
+	incident, ok := st.open[key]
+	if !ok {
+		return 0
+	}
+	delete(st.open, key)
+
+	now := time.Now()
+	duration := now.Sub(incident.StartedAt)
+	incident.EndedAt = &now
+	incident.Duration = duration.Seconds()
+	incident.EndValue = value
+	incident.Resolution = resolution
+	if alert == models.AlertOffline {
+		incident.PeakValue = incident.Duration // 离线告警的严重程度即离线时长
+	}
+	st.persist(incident)
+
+	return duration
+}
+
+// endAll 结束节点所有未结束的告警记录（节点被删除时使用）
+func (st *incidentStore) endAll(nodeKey string, resolution string) {
+	st.mutex.Lock()
+	var alerts []string
+	for key, incident := range st.open {
+		if strings.HasPrefix(key, nodeKey+"|") {
+			alerts = append(alerts, incident.Alert)
+		}
+	}
+	st.mutex.Unlock()
+
+	for _, alert := range alerts {
+		st.end(nodeKey, alert, 0, resolution)
+	}
+}
+
+// incidentQuery /api/incidents 的查询条件
+type incidentQuery struct {
+	node  string // 节点标识或主机名
+	alert string
+	since time.Time
+	until time.Time
+	limit int
+}
+
+// query 返回与时间范围有交集的记录，按开始时间倒序
+func (st *incidentStore) query(q incidentQuery) []models.Incident {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	now := time.Now()
+	result := []models.Incident{}
+	for i := len(st.incidents) - 1; i >= 0; i-- {
+		incident := *st.incidents[i]
+		if q.node != "" && incident.NodeID != q.node && incident.Hostname != q.node {
+			continue
+		}
+		if q.alert != "" && incident.Alert != q.alert {
+			continue
+		}
+		if !q.until.IsZero() && incident.StartedAt.After(q.until) {
+			continue
+		}
+		if !q.since.IsZero() && incident.EndedAt != nil && incident.EndedAt.Before(q.since) {
+			continue
+		}
+		if incident.EndedAt == nil {
+			incident.Duration = now.Sub(incident.StartedAt).Seconds()
+		}
+
+		result = append(result, incident)
+		if q.limit > 0 && len(result) >= q.limit {
+			break
+		}
+	}
+	return result
+}
+
+// parseTimeParam 解析 RFC3339 或 Unix 秒时间戳
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// handleIncidents 查询告警记录，支持 node、alert、since、until、limit 参数
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.sendResponse(w, false, s.tr(r, "api.get_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleViewer) {
+		return
+	}
+
+	values := r.URL.Query()
+	q := incidentQuery{
+		node:  values.Get("node"),
+		alert: values.Get("alert"),
+		limit: 100,
+	}
+
+	var err error
+	if q.since, err = parseTimeParam(values.Get("since")); err != nil {
+		s.sendResponse(w, false, s.tr(r, "api.invalid_param", "since", values.Get("since")), nil)
+		return
+	}
+	if q.until, err = parseTimeParam(values.Get("until")); err != nil {
+		s.sendResponse(w, false, s.tr(r, "api.invalid_param", "until", values.Get("until")), nil)
+		return
+	}
+	if values.Get("limit") != "" {
+		if q.limit, err = parseIntParam(values, "limit"); err != nil {
+			s.sendResponse(w, false, s.trErr(r, err), nil)
+			return
+		}
+	}
+
+	s.sendResponse(w, true, s.tr(r, "api.incidents_ok"), s.incidents.query(q))
+}
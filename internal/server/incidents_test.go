@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func TestIncidentStorePersistsInBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.jsonl")
+	store, err := loadIncidentStore(path, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &models.NodeStatus{NodeID: "n1", Hostname: "h1"}
+	store.start("n1", node, models.AlertCPU, time.Now(), 96, 95)
+	store.end("n1", models.AlertCPU, 50, models.ResolutionRecovered)
+	store.start("n1", node, models.AlertMemory, time.Now(), 91, 90)
+	if err := store.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte{'\n'}); lines != 3 {
+		t.Fatalf("文件有 %d 行，期望每次状态变化追加一行共 3 行", lines)
+	}
+
+	reloaded, err := loadIncidentStore(path, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.flush()
+	incidents := reloaded.query(incidentQuery{})
+	if len(incidents) != 2 {
+		t.Fatalf("重新加载得到 %d 条记录，期望 2 条", len(incidents))
+	}
+	for _, incident := range incidents {
+		if incident.EndedAt == nil {
+			t.Errorf("记录 %s 未结束，重启后应标记结束", incident.Alert)
+		}
+		if incident.Alert == models.AlertMemory && incident.Resolution != models.ResolutionServerRestart {
+			t.Errorf("重启前未结束的记录 resolution = %q", incident.Resolution)
+		}
+	}
+}
+
+func TestIncidentStoreCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.jsonl")
+	store, err := loadIncidentStore(path, 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &models.NodeStatus{NodeID: "n1", Hostname: "h1"}
+	for i := 0; i < incidentCompactWrites/2+1; i++ {
+		store.start("n1", node, models.AlertCPU, time.Now(), 96, 95)
+		store.end("n1", models.AlertCPU, 50, models.ResolutionRecovered)
+	}
+	if err := store.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第 incidentCompactWrites 次写入触发重写，之后只追加了一条开始和一条结束
+	want := incidentCompactWrites/2 + 1
+	if lines := bytes.Count(data, []byte{'\n'}); lines != want+1 {
+		t.Fatalf("文件有 %d 行，期望整理后为 %d 行", lines, want+1)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+)
+
+// lang 返回接口提示使用的语言：lang 参数优先，其次 Accept-Language 请求头，最后为服务端配置
+func (s *Server) lang(r *http.Request) i18n.Lang {
+	if lang, ok := i18n.ParseLang(r.URL.Query().Get("lang")); ok {
+		return lang
+	}
+	return i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"), s.defaultLang())
+}
+
+// defaultLang 返回服务端配置的语言，用于无法按请求选择语言的场景（如gRPC）
+func (s *Server) defaultLang() i18n.Lang {
+	return i18n.Resolve(s.cfg().Language)
+}
+
+// tr 返回请求语言下的接口提示
+func (s *Server) tr(r *http.Request, key string, args ...interface{}) string {
+	return s.lang(r).T(key, args...)
+}
+
+// trErr 返回错误在请求语言下的文本
+func (s *Server) trErr(r *http.Request, err error) string {
+	return i18n.Localize(s.lang(r), err)
+}
+
+// notifyLocale 返回Telegram通知的语言和时区，telegram 中未单独设置时使用服务端配置
+func notifyLocale(config *models.ServerConfig) (i18n.Lang, *time.Location) {
+	lang := i18n.Resolve(config.Telegram.Language, config.Language)
+
+	timezone := config.Telegram.Timezone
+	if timezone == "" {
+		timezone = config.Timezone
+	}
+	location := time.Local
+	if timezone != "" {
+		// 配置校验已检查时区，加载失败时退回本地时区
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			location = loc
+		}
+	}
+	return lang, location
+}
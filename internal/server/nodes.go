@@ -0,0 +1,142 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"bandwidth-monitor/internal/compress"
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+)
+
+// handleNodes 节点管理：POST 修改显示名称或管理状态，DELETE ?node= 删除节点
+func (s *Server) handleNodes(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, models.RoleAdmin) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req models.NodeUpdateRequest
+		if err := compress.DecodeJSONBody(r, &req); err != nil {
+			s.sendResponse(w, false, s.tr(r, "api.invalid_json"), nil)
+			return
+		}
+		node, err := s.updateNode(req)
+		if err != nil {
+			s.sendResponse(w, false, s.trErr(r, err), nil)
+			return
+		}
+		s.sendResponse(w, true, s.tr(r, "api.node_updated"), node)
+	case http.MethodDelete:
+		node, err := s.deleteNode(r.URL.Query().Get("node"))
+		if err != nil {
+			s.sendResponse(w, false, s.trErr(r, err), nil)
+			return
+		}
+		adminLog.Info("已删除节点", "node", node.Name(), "node_id", nodeIDLabel(&node))
+		s.sendResponse(w, true, s.tr(r, "api.node_deleted"), node)
+	default:
+		s.sendResponse(w, false, s.tr(r, "api.method_not_allowed"), nil)
+	}
+}
+
+// findNode 按节点标识或主机名查找节点（须持有 s.mutex）
+func (s *Server) findNode(ref string) (string, *models.NodeStatus, error) {
+	if ref == "" {
+		return "", nil, i18n.NewError("api.missing_node")
+	}
+	if node, ok := s.nodes[ref]; ok {
+		return ref, node, nil
+	}
+
+	var foundKey string
+	var found *models.NodeStatus
+	for key, node := range s.nodes {
+		if node.Hostname != ref && node.DisplayName != ref {
+			continue
+		}
+		if found != nil {
+			return "", nil, i18n.NewError("api.ambiguous_node", ref)
+		}
+		foundKey, found = key, node
+	}
+	if found == nil {
+		return "", nil, i18n.NewError("api.node_not_found")
+	}
+	return foundKey, found, nil
+}
+
+func (s *Server) updateNode(req models.NodeUpdateRequest) (models.NodeStatus, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, node, err := s.findNode(req.Node)
+	if err != nil {
+		return models.NodeStatus{}, err
+	}
+
+	if req.State != nil {
+		switch *req.State {
+		case models.NodeStateActive, models.NodeStateDisabled, models.NodeStateDecommissioned:
+		default:
+			return models.NodeStatus{}, i18n.NewError("api.invalid_node_state", *req.State)
+		}
+	}
+
+	if req.DisplayName != nil && *req.DisplayName != node.DisplayName {
+		adminLog.Info("修改节点显示名称", "node", node.Hostname, "old", node.DisplayName, "new", *req.DisplayName)
+		node.DisplayName = *req.DisplayName
+	}
+	if req.State != nil && *req.State != node.State {
+		adminLog.Info("修改节点管理状态", "node", node.Name(), "old", node.State, "new", *req.State)
+		node.State = *req.State
+	}
+
+	s.publishStatus(node)
+	return *node, nil
+}
+
+// deleteNode 删除节点；节点再次上报时会作为新节点出现
+func (s *Server) deleteNode(ref string) (models.NodeStatus, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, node, err := s.findNode(ref)
+	if err != nil {
+		return models.NodeStatus{}, err
+	}
+	s.removeNode(key, node)
+	return *node, nil
+}
+
+// removeNode 从节点表中移除节点并发布事件（须持有 s.mutex）
+func (s *Server) removeNode(key string, node *models.NodeStatus) {
+	delete(s.nodes, key)
+	delete(s.heartbeatSeq, key)
+	s.resetRuleAlerts(node, models.ResolutionRemoved, nil)
+	s.incidents.endAll(key, models.ResolutionRemoved)
+	s.emitNodeEvent(models.EventNodeRemoved, node, "", 0, 0)
+}
+
+// pruneNodes 删除离线超过 PruneOfflineDays 天的节点并发送通知（须持有 s.mutex）
+func (s *Server) pruneNodes(now time.Time) {
+	if s.cfg().PruneOfflineDays <= 0 {
+		return
+	}
+	limit := time.Duration(s.cfg().PruneOfflineDays) * 24 * time.Hour
+
+	for key, node := range s.nodes {
+		if node.IsOnline || now.Sub(node.LastSeen) <= limit {
+			continue
+		}
+
+		s.removeNode(key, node)
+		adminLog.Info("离线节点已自动删除", "node", node.Name(), "prune_offline_days", s.cfg().PruneOfflineDays)
+		if s.bot() != nil {
+			if err := s.bot().SendNodePrunedNotice(node.Name(), now.Sub(node.LastSeen)); err != nil {
+				notifyLog.Error("发送节点删除通知失败", "node", node.Name(), "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+)
+
+func errText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return i18n.Localize(i18n.Resolve("zh"), err)
+}
+
+func TestUpdateNode(t *testing.T) {
+	s := newTestServer(t)
+	s.nodes["n1"] = &models.NodeStatus{NodeID: "n1", Hostname: "web", IsOnline: true}
+	s.nodes["n2"] = &models.NodeStatus{NodeID: "n2", Hostname: "web", IsOnline: true}
+	s.nodes["n3"] = &models.NodeStatus{NodeID: "n3", Hostname: "db", DisplayName: "主库", IsOnline: true}
+
+	str := func(v string) *string { return &v }
+	tests := []struct {
+		name  string
+		req   models.NodeUpdateRequest
+		err   string
+		node  string // 成功时被修改的节点
+		state string
+		title string
+	}{
+		{"缺少节点", models.NodeUpdateRequest{State: str("disabled")}, "缺少节点参数", "", "", ""},
+		{"主机名重复", models.NodeUpdateRequest{Node: "web", State: str("disabled")}, "web 对应多个节点，请使用节点标识", "", "", ""},
+		{"节点不存在", models.NodeUpdateRequest{Node: "cache"}, "节点不存在", "", "", ""},
+		{"无效状态", models.NodeUpdateRequest{Node: "n1", State: str("paused")}, "无效的节点状态: paused", "", "", ""},
+		{"按节点标识修改", models.NodeUpdateRequest{Node: "n1", State: str("disabled")}, "", "n1", "disabled", ""},
+		{"按主机名修改", models.NodeUpdateRequest{Node: "db", DisplayName: str("数据库")}, "", "n3", "", "数据库"},
+		{"按显示名称修改", models.NodeUpdateRequest{Node: "数据库", State: str("decommissioned")}, "", "n3", "decommissioned", "数据库"},
+		{"恢复监控", models.NodeUpdateRequest{Node: "n1", State: str("")}, "", "n1", "", ""},
+	}
+	for _, tt := range tests {
+		node, err := s.updateNode(tt.req)
+		if got := errText(err); got != tt.err {
+			t.Errorf("%s: 错误 = %q，期望 %q", tt.name, got, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		stored := s.nodes[tt.node]
+		if node.NodeID != tt.node || stored.State != tt.state || stored.DisplayName != tt.title {
+			t.Errorf("%s: 节点 %s 状态 %q 名称 %q，期望 %s %q %q", tt.name, node.NodeID, stored.State, stored.DisplayName, tt.node, tt.state, tt.title)
+		}
+	}
+
+	// 无效状态不能部分生效
+	if _, err := s.updateNode(models.NodeUpdateRequest{Node: "n1", DisplayName: str("x"), State: str("bad")}); err == nil || s.nodes["n1"].DisplayName != "" {
+		t.Errorf("无效状态时显示名称被修改为 %q", s.nodes["n1"].DisplayName)
+	}
+}
+
+func TestDeleteNode(t *testing.T) {
+	s := newTestServer(t)
+	s.nodes["n1"] = &models.NodeStatus{NodeID: "n1", Hostname: "web"}
+	s.nodes["n2"] = &models.NodeStatus{NodeID: "n2", Hostname: "web"}
+	s.heartbeatSeq["n1"] = 7
+
+	if _, err := s.deleteNode("web"); errText(err) != "web 对应多个节点，请使用节点标识" {
+		t.Fatalf("主机名重复时错误 = %v", err)
+	}
+	if _, err := s.deleteNode(""); errText(err) != "缺少节点参数" {
+		t.Fatalf("缺少节点时错误 = %v", err)
+	}
+
+	node, err := s.deleteNode("n1")
+	if err != nil || node.NodeID != "n1" {
+		t.Fatalf("删除 n1: %v %+v", err, node)
+	}
+	if _, ok := s.nodes["n1"]; ok {
+		t.Error("n1 仍在节点表中")
+	}
+	if _, ok := s.heartbeatSeq["n1"]; ok {
+		t.Error("n1 的心跳序号未清除")
+	}
+
+	// 只剩一个同名节点后可以按主机名删除
+	if node, err := s.deleteNode("web"); err != nil || node.NodeID != "n2" {
+		t.Fatalf("按主机名删除: %v %+v", err, node)
+	}
+	if len(s.nodes) != 0 {
+		t.Errorf("剩余 %d 个节点", len(s.nodes))
+	}
+}
+
+func TestPruneNodes(t *testing.T) {
+	s := newTestServer(t)
+	now := time.Now()
+	day := 24 * time.Hour
+	s.nodes["fresh"] = &models.NodeStatus{NodeID: "fresh", Hostname: "a", IsOnline: false, LastSeen: now.Add(-2 * day)}
+	s.nodes["edge"] = &models.NodeStatus{NodeID: "edge", Hostname: "b", IsOnline: false, LastSeen: now.Add(-7 * day)}
+	s.nodes["stale"] = &models.NodeStatus{NodeID: "stale", Hostname: "c", IsOnline: false, LastSeen: now.Add(-8 * day)}
+	s.nodes["online"] = &models.NodeStatus{NodeID: "online", Hostname: "d", IsOnline: true, LastSeen: now.Add(-30 * day)}
+	for key, node := range s.nodes {
+		s.incidents.start(key, node, models.AlertOffline, node.LastSeen, 0, 0)
+	}
+
+	// 未配置时不删除
+	s.pruneNodes(now)
+	if len(s.nodes) != 4 {
+		t.Fatalf("未配置 prune_offline_days 时删除了节点，剩余 %d 个", len(s.nodes))
+	}
+
+	s.cfg().PruneOfflineDays = 7
+	s.pruneNodes(now)
+	for _, key := range []string{"fresh", "edge", "online"} {
+		if _, ok := s.nodes[key]; !ok {
+			t.Errorf("节点 %s 不应被删除", key)
+		}
+	}
+	if _, ok := s.nodes["stale"]; ok {
+		t.Error("离线超过 7 天的节点未被删除")
+	}
+
+	for _, incident := range s.incidents.query(incidentQuery{}) {
+		ended := incident.EndedAt != nil
+		if incident.NodeID == "stale" {
+			if !ended || incident.Resolution != models.ResolutionRemoved {
+				t.Errorf("被删除节点的事件 ended=%v resolution=%q，期望以 %q 结束", ended, incident.Resolution, models.ResolutionRemoved)
+			}
+		} else if ended {
+			t.Errorf("节点 %s 的事件被结束: %q", incident.NodeID, incident.Resolution)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"time"
+
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/telegram"
+)
+
+// alertMessage 生成一次告警通知的内容，持续时长、附加信息等由调用方补充（须持有 s.mutex）
+func (s *Server) alertMessage(node *models.NodeStatus, alert, state string, value, threshold float64) models.AlertMessage {
+	return models.AlertMessage{
+		Alert:        alert,
+		State:        state,
+		Node:         *node,
+		Hostname:     node.Name(),
+		Tags:         node.Tags,
+		Value:        value,
+		Threshold:    threshold,
+		Time:         time.Now(),
+		DashboardURL: s.cfg().DashboardLink(node.NodeID, node.Hostname),
+	}
+}
+
+// sendAlert 发送告警通知，调用方已通过 shouldNotify 检查
+func (s *Server) sendAlert(msg models.AlertMessage) {
+	if err := s.bot().SendAlert(msg); err != nil {
+		notifyLog.Error("发送告警通知失败", "node", msg.Node.Hostname, "alert", msg.Alert, "state", msg.State, "error", err)
+	}
+}
+
+// configureBot 按配置设置机器人的语言、时区和通知模板（含告警规则自带的模板）
+func configureBot(bot *telegram.Bot, config *models.ServerConfig) (*telegram.Bot, error) {
+	templates, err := models.CompileAlertTemplates(config.EffectiveAlertTemplates())
+	if err != nil {
+		return nil, err
+	}
+	return bot.WithLocale(notifyLocale(config)).WithTemplates(templates), nil
+}
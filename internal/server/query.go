@@ -0,0 +1,227 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+)
+
+// statusQuery /api/status 的筛选、排序与分页参数
+type statusQuery struct {
+	online   *bool
+	alerting *bool
+	alert    string
+	tags     map[string]string // 值为空表示只要求存在该标签
+	hostname string            // 主机名或显示名称的glob
+	sortKey  string
+	desc     bool
+	offset   int
+	limit    int
+}
+
+// 可排序字段，数值字段按 float64 比较
+var nodeSortFields = map[string]func(n *models.NodeStatus) float64{
+	"last_seen":          func(n *models.NodeStatus) float64 { return float64(n.LastSeen.UnixNano()) },
+	"cpu_percent":        func(n *models.NodeStatus) float64 { return n.Metrics.CPUPercent },
+	"memory_percent":     func(n *models.NodeStatus) float64 { return n.Metrics.MemoryPercent() },
+	"network_in_mbps":    func(n *models.NodeStatus) float64 { return float64(n.Metrics.NetworkInBps) / 125000.0 },
+	"network_out_mbps":   func(n *models.NodeStatus) float64 { return float64(n.Metrics.NetworkOutBps) / 125000.0 },
+	"uptime_seconds":     func(n *models.NodeStatus) float64 { return float64(n.Metrics.UptimeSeconds) },
+	"clock_skew_seconds": func(n *models.NodeStatus) float64 { return float64(n.ClockSkewSeconds) },
+}
+
+func parseBoolParam(values url.Values, name string) (*bool, error) {
+	v := values.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, i18n.NewError("api.invalid_param", name, v)
+	}
+	return &b, nil
+}
+
+func parseIntParam(values url.Values, name string) (int, error) {
+	v := values.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, i18n.NewError("api.invalid_param", name, v)
+	}
+	return n, nil
+}
+
+func parseStatusQuery(values url.Values) (*statusQuery, error) {
+	q := &statusQuery{
+		alert:    values.Get("alert"),
+		hostname: values.Get("hostname"),
+	}
+
+	var err error
+	if q.online, err = parseBoolParam(values, "online"); err != nil {
+		return nil, err
+	}
+	if q.alerting, err = parseBoolParam(values, "alerting"); err != nil {
+		return nil, err
+	}
+	if q.offset, err = parseIntParam(values, "offset"); err != nil {
+		return nil, err
+	}
+	if q.limit, err = parseIntParam(values, "limit"); err != nil {
+		return nil, err
+	}
+
+	if q.hostname != "" {
+		if _, err := path.Match(q.hostname, ""); err != nil {
+			return nil, i18n.NewError("api.invalid_hostname_glob", q.hostname)
+		}
+	}
+
+	// tag=role:db 要求标签值相等，tag=role 只要求存在，可重复指定
+	for _, tag := range values["tag"] {
+		if q.tags == nil {
+			q.tags = make(map[string]string)
+		}
+		key, value, _ := strings.Cut(tag, ":")
+		q.tags[key] = value
+	}
+
+	// sort=cpu_percent 升序，sort=-cpu_percent 降序
+	if sortKey := values.Get("sort"); sortKey != "" {
+		q.desc = strings.HasPrefix(sortKey, "-")
+		q.sortKey = strings.TrimPrefix(sortKey, "-")
+		if _, ok := nodeSortFields[q.sortKey]; !ok && q.sortKey != "hostname" {
+			return nil, i18n.NewError("api.unsupported_sort", q.sortKey)
+		}
+	}
+
+	return q, nil
+}
+
+func (q *statusQuery) match(node *models.NodeStatus) bool {
+	if q.online != nil && node.IsOnline != *q.online {
+		return false
+	}
+
+	alerts := node.ActiveAlerts()
+	if q.alerting != nil && (len(alerts) > 0) != *q.alerting {
+		return false
+	}
+	if q.alert != "" {
+		found := false
+		for _, alert := range alerts {
+			found = found || alert == q.alert
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for key, value := range q.tags {
+		actual, ok := node.Tags[key]
+		if !ok || (value != "" && actual != value) {
+			return false
+		}
+	}
+
+	if q.hostname != "" {
+		hostMatch, _ := path.Match(q.hostname, node.Hostname)
+		nameMatch, _ := path.Match(q.hostname, node.DisplayName)
+		if !hostMatch && !nameMatch {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply 筛选、排序并分页；未指定排序时按名称排序保证分页稳定
+func (q *statusQuery) apply(nodes []models.NodeStatus) *models.NodeList {
+	matched := nodes[:0]
+	for i := range nodes {
+		if q.match(&nodes[i]) {
+			matched = append(matched, nodes[i])
+		}
+	}
+
+	less := func(a, b *models.NodeStatus) bool {
+		if a.Name() != b.Name() {
+			return a.Name() < b.Name()
+		}
+		return a.NodeID < b.NodeID
+	}
+	if value, ok := nodeSortFields[q.sortKey]; ok {
+		byName := less
+		less = func(a, b *models.NodeStatus) bool {
+			if va, vb := value(a), value(b); va != vb {
+				return va < vb
+			}
+			return byName(a, b)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if q.desc {
+			return less(&matched[j], &matched[i])
+		}
+		return less(&matched[i], &matched[j])
+	})
+
+	list := &models.NodeList{Total: len(matched), Offset: q.offset, Limit: q.limit}
+	if q.offset < len(matched) {
+		matched = matched[q.offset:]
+		if q.limit > 0 && q.limit < len(matched) {
+			matched = matched[:q.limit]
+		}
+		list.Nodes = matched
+	}
+	if list.Nodes == nil {
+		list.Nodes = []models.NodeStatus{}
+	}
+	return list
+}
+
+// handleSummary 返回全部节点的汇总统计，供大屏展示
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.sendResponse(w, false, s.tr(r, "api.get_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleViewer) {
+		return
+	}
+
+	summary := &models.FleetSummary{Alerts: make(map[string]int)}
+	for _, node := range s.snapshotNodes() {
+		summary.Total++
+		if node.State != models.NodeStateActive {
+			summary.Disabled++
+		}
+
+		alerts := node.ActiveAlerts()
+		for _, alert := range alerts {
+			summary.Alerts[alert]++
+		}
+
+		if !node.IsOnline {
+			summary.Offline++
+			continue
+		}
+		summary.Online++
+		if len(alerts) > 0 {
+			summary.Alerting++
+		}
+		summary.NetworkInMbps += float64(node.Metrics.NetworkInBps) / 125000.0
+		summary.NetworkOutMbps += float64(node.Metrics.NetworkOutBps) / 125000.0
+	}
+
+	s.sendResponse(w, true, s.tr(r, "api.summary_ok"), summary)
+}
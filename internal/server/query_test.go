@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+)
+
+func TestParseStatusQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // 期望的中文错误，空表示合法
+	}{
+		{"sort=cpu_percent", ""},
+		{"sort=-hostname", ""},
+		{"sort=bogus", "不支持的排序字段: bogus"},
+		{"sort=-", "不支持的排序字段: "},
+		{"limit=0&offset=0", ""},
+		{"limit=-1", "参数 limit 无效: -1"},
+		{"limit=1.5", "参数 limit 无效: 1.5"},
+		{"offset=abc", "参数 offset 无效: abc"},
+		{"offset=-3", "参数 offset 无效: -3"},
+		{"online=maybe", "参数 online 无效: maybe"},
+		{"alerting=2", "参数 alerting 无效: 2"},
+		{"hostname=[", "主机名规则无效: ["},
+		{"hostname=web-*&tag=role:db&tag=env", ""},
+	}
+	for _, tt := range tests {
+		values, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = parseStatusQuery(values)
+		got := ""
+		if err != nil {
+			got = i18n.Localize(i18n.Resolve("zh"), err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: 错误 = %q，期望 %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func testNodes() []models.NodeStatus {
+	now := time.Now()
+	return []models.NodeStatus{
+		{NodeID: "n1", Hostname: "web-1", IsOnline: true, LastSeen: now, Tags: map[string]string{"role": "web"}, Metrics: models.SystemMetrics{CPUPercent: 20}},
+		{NodeID: "n2", Hostname: "web-2", IsOnline: true, LastSeen: now, Tags: map[string]string{"role": "web", "env": "prod"}, CPUAlerted: true, Metrics: models.SystemMetrics{CPUPercent: 97}},
+		{NodeID: "n3", Hostname: "db-1", IsOnline: true, LastSeen: now, Tags: map[string]string{"role": "db", "env": "prod"}, Metrics: models.SystemMetrics{CPUPercent: 55}},
+		{NodeID: "n4", Hostname: "db-2", IsOnline: false, LastSeen: now.Add(-time.Hour), Tags: map[string]string{"role": "db"}},
+		{NodeID: "n5", Hostname: "host-5", DisplayName: "web-legacy", IsOnline: true, LastSeen: now, Metrics: models.SystemMetrics{CPUPercent: 55}},
+	}
+}
+
+func TestStatusQueryApply(t *testing.T) {
+	tests := []struct {
+		query string
+		total int
+		want  []string // 分页后的节点标识，按返回顺序
+	}{
+		{"", 5, []string{"n3", "n4", "n1", "n2", "n5"}},
+		{"online=false", 1, []string{"n4"}},
+		{"alerting=true", 2, []string{"n4", "n2"}},
+		{"alert=cpu", 1, []string{"n2"}},
+		{"alert=memory", 0, []string{}},
+		{"tag=role:web", 2, []string{"n1", "n2"}},
+		{"tag=env", 2, []string{"n3", "n2"}},
+		{"tag=role:db&tag=env:prod", 1, []string{"n3"}},
+		{"hostname=web-*", 3, []string{"n1", "n2", "n5"}},
+		{"hostname=web-*&online=true&tag=role:web&alerting=false", 1, []string{"n1"}},
+		{"sort=cpu_percent", 5, []string{"n4", "n1", "n3", "n5", "n2"}},
+		{"sort=-cpu_percent", 5, []string{"n2", "n5", "n3", "n1", "n4"}},
+		{"sort=-hostname", 5, []string{"n5", "n2", "n1", "n4", "n3"}},
+		{"sort=-cpu_percent&limit=2", 5, []string{"n2", "n5"}},
+		{"sort=-cpu_percent&offset=3&limit=10", 5, []string{"n1", "n4"}},
+		{"offset=4&limit=1", 5, []string{"n5"}},
+		{"offset=5", 5, []string{}},
+		{"offset=100&limit=2", 5, []string{}},
+		{"online=true&offset=1&limit=2", 4, []string{"n1", "n2"}},
+	}
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		query, err := parseStatusQuery(values)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		list := query.apply(testNodes())
+		if list.Total != tt.total {
+			t.Errorf("%s: total = %d，期望 %d", tt.query, list.Total, tt.total)
+		}
+		if list.Nodes == nil {
+			t.Errorf("%s: nodes 为 nil，应返回空数组", tt.query)
+		}
+		got := make([]string, 0, len(list.Nodes))
+		for _, node := range list.Nodes {
+			got = append(got, node.NodeID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: 节点 = %v，期望 %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHandleStatusRejectsBadQuery(t *testing.T) {
+	s := newTestServer(t)
+	s.cfg().APIKeys = []models.APIKey{{Name: "dash", Key: "viewer-key", Role: models.RoleViewer}}
+	for _, node := range testNodes() {
+		node := node
+		s.nodes[node.NodeID] = &node
+	}
+
+	tests := []struct {
+		query   string
+		code    int
+		message string
+	}{
+		{"sort=bogus", http.StatusBadRequest, "不支持的排序字段: bogus"},
+		{"sort=bogus&lang=en", http.StatusBadRequest, "Unsupported sort field: bogus"},
+		{"limit=-1", http.StatusBadRequest, "参数 limit 无效: -1"},
+		{"offset=x&lang=en", http.StatusBadRequest, "Invalid parameter offset: x"},
+		{"key=ip", http.StatusBadRequest, "参数 key 无效: ip"},
+		{"online=true&sort=-cpu_percent&limit=1", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/status?"+tt.query, nil)
+		req.Header.Set("Authorization", "Bearer viewer-key")
+		w := httptest.NewRecorder()
+		s.handleStatus(w, req)
+
+		var resp struct {
+			Success bool            `json:"success"`
+			Message string          `json:"message"`
+			Data    models.NodeList `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if w.Code != tt.code {
+			t.Errorf("%s: 状态码 = %d，期望 %d", tt.query, w.Code, tt.code)
+		}
+		if tt.code != http.StatusOK {
+			if resp.Success || resp.Message != tt.message {
+				t.Errorf("%s: success=%v message=%q，期望失败并提示 %q", tt.query, resp.Success, resp.Message, tt.message)
+			}
+			continue
+		}
+		if !resp.Success || resp.Data.Total != 4 || len(resp.Data.Nodes) != 1 || resp.Data.Nodes[0].NodeID != "n2" {
+			t.Errorf("%s: 返回 %+v", tt.query, resp)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/logging"
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/telegram"
+)
+
+// WatchConfig 每5秒检查配置文件修改时间，有变化时重载
+func (s *Server) WatchConfig(path string) {
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(modTime) {
+			continue
+		}
+		modTime = info.ModTime()
+
+		if err := s.Reload(path); err != nil {
+			adminLog.Error("重载配置失败，继续使用原配置", "error", err)
+		}
+	}
+}
+
+// Reload 重新读取配置文件并替换当前配置；配置无效时保留原配置。
+// 监听地址和gRPC证书在启动时绑定，变更后需重启服务端才能生效。
+func (s *Server) Reload(path string) error {
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+
+	newConfig, err := models.ReadServerConfig(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	if err := newConfig.Validate(); err != nil {
+		return fmt.Errorf("配置无效: %v", err)
+	}
+
+	oldConfig := s.cfg()
+	changes := configChanges(oldConfig, newConfig)
+	if len(changes) == 0 {
+		adminLog.Info("配置文件无变化")
+		return nil
+	}
+
+	// 监听地址和gRPC证书保持原值，使 cfg() 反映实际生效的配置
+	restart := false
+	for _, listen := range []struct {
+		name     string
+		old, new *string
+	}{
+		{"listen", &oldConfig.Listen, &newConfig.Listen},
+		{"grpc_listen", &oldConfig.GRPCListen, &newConfig.GRPCListen},
+		{"heartbeat_listen", &oldConfig.HeartbeatListen, &newConfig.HeartbeatListen},
+		{"grpc_tls.cert_file", &oldConfig.GRPCTLS.CertFile, &newConfig.GRPCTLS.CertFile},
+		{"grpc_tls.key_file", &oldConfig.GRPCTLS.KeyFile, &newConfig.GRPCTLS.KeyFile},
+	} {
+		if *listen.old != *listen.new {
+			adminLog.Warn("监听配置变更需重启服务端才能生效", "field", listen.name, "value", *listen.new)
+			*listen.new = *listen.old
+			restart = true
+		}
+	}
+
+	// Telegram token 或聊天变化时重建机器人，失败则不应用本次配置
+	tgBot := s.bot()
+	if newConfig.Telegram.BotToken != oldConfig.Telegram.BotToken || newConfig.Telegram.ChatID != oldConfig.Telegram.ChatID {
+		tgBot = nil
+		if newConfig.Telegram.BotToken != "" {
+			if tgBot, err = telegram.NewBot(newConfig.Telegram.BotToken, newConfig.Telegram.ChatID); err != nil {
+				return fmt.Errorf("初始化Telegram机器人失败: %v", err)
+			}
+		}
+	}
+	if tgBot, err = configureBot(tgBot, newConfig); err != nil {
+		return err
+	}
+	alertRules, err := compileAlertRules(newConfig)
+	if err != nil {
+		return err
+	}
+
+	if newConfig.Log != oldConfig.Log {
+		if err := logging.Setup(newConfig.Log); err != nil {
+			return err
+		}
+	}
+
+	if s.incidents != nil && (newConfig.IncidentFile != oldConfig.IncidentFile ||
+		newConfig.IncidentRetentionDays != oldConfig.IncidentRetentionDays) {
+		if err := s.incidents.configure(newConfig.IncidentFile, newConfig.IncidentRetentionDays); err != nil {
+			return err
+		}
+	}
+
+	s.configMutex.Lock()
+	s.config = newConfig
+	s.tgBot = tgBot
+	s.rules = alertRules
+	s.configMutex.Unlock()
+	s.pruneRuleAlerts()
+
+	for _, change := range changes {
+		adminLog.Info("配置变更", "change", change)
+	}
+	if restart {
+		adminLog.Info("配置已重载", "restart_required", true)
+	} else {
+		adminLog.Info("配置已重载")
+	}
+	return nil
+}
+
+// 值不输出到日志的配置项
+var secretConfigKeys = []string{"password", "key", "bot_token"}
+
+// configChanges 按JSON字段路径列出两份配置的差异，如 thresholds.cpu_percent: 95 -> 90
+func configChanges(oldConfig, newConfig *models.ServerConfig) []string {
+	oldFields, newFields := flattenConfig(oldConfig), flattenConfig(newConfig)
+
+	var changes []string
+	for path, newValue := range newFields {
+		oldValue, ok := oldFields[path]
+		if ok && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, formatConfigChange(path, oldValue, newValue, ok))
+	}
+	for path, oldValue := range oldFields {
+		if _, ok := newFields[path]; !ok {
+			changes = append(changes, fmt.Sprintf("%s: %s -> (已删除)", path, formatConfigValue(path, oldValue)))
+		}
+	}
+
+	sort.Strings(changes)
+	return changes
+}
+
+func formatConfigChange(path string, oldValue, newValue interface{}, existed bool) string {
+	if !existed {
+		return fmt.Sprintf("%s: (新增) %s", path, formatConfigValue(path, newValue))
+	}
+	if isSecretConfigKey(path) {
+		return fmt.Sprintf("%s: 已修改", path)
+	}
+	return fmt.Sprintf("%s: %s -> %s", path, formatConfigValue(path, oldValue), formatConfigValue(path, newValue))
+}
+
+func formatConfigValue(path string, value interface{}) string {
+	if isSecretConfigKey(path) {
+		return "***"
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func isSecretConfigKey(path string) bool {
+	name := path[strings.LastIndex(path, ".")+1:]
+	for _, key := range secretConfigKeys {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenConfig 将配置展开为 字段路径 -> 值，数组元素以下标区分，如 api_keys.0.role
+func flattenConfig(config *models.ServerConfig) map[string]interface{} {
+	var tree interface{}
+	data, _ := json.Marshal(config)
+	json.Unmarshal(data, &tree)
+
+	fields := make(map[string]interface{})
+	var walk func(prefix string, node interface{})
+	walk = func(prefix string, node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				walk(joinConfigPath(prefix, key), child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(joinConfigPath(prefix, fmt.Sprint(i)), child)
+			}
+		default:
+			fields[prefix] = v
+		}
+	}
+	walk("", tree)
+	return fields
+}
+
+func joinConfigPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
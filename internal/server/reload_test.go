@@ -0,0 +1,108 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"bandwidth-monitor/internal/models"
+)
+
+func TestConfigChanges(t *testing.T) {
+	oldConfig := &models.ServerConfig{
+		Password:   "old-password",
+		Thresholds: models.Threshold{CPUPercent: 95},
+		Telegram:   models.TGConfig{BotToken: "old-token", ChatID: 1},
+		APIKeys:    []models.APIKey{{Name: "ops", Key: "old-key", Role: models.RoleAdmin}},
+	}
+	newConfig := &models.ServerConfig{
+		Password:   "new-password",
+		Thresholds: models.Threshold{CPUPercent: 90},
+		Telegram:   models.TGConfig{BotToken: "new-token", ChatID: 1},
+		APIKeys: []models.APIKey{
+			{Name: "ops", Key: "new-key", Role: models.RoleAdmin},
+			{Name: "dash", Key: "added-key", Role: models.RoleViewer},
+		},
+	}
+
+	changes := configChanges(oldConfig, newConfig)
+	joined := strings.Join(changes, "\n")
+	for _, want := range []string{
+		"thresholds.cpu_percent: 95 -> 90",
+		"password: 已修改",
+		"telegram.bot_token: 已修改",
+		"api_keys.0.key: 已修改",
+		"api_keys.1.key: (新增) ***",
+		`api_keys.1.role: (新增) "viewer"`,
+	} {
+		if !containsLine(changes, want) {
+			t.Errorf("缺少变更 %q，实际为:\n%s", want, joined)
+		}
+	}
+	for _, secret := range []string{"password", "token", "key"} {
+		for _, prefix := range []string{"old-", "new-", "added-"} {
+			if strings.Contains(joined, prefix+secret) {
+				t.Errorf("变更中泄露了敏感值 %s%s", prefix, secret)
+			}
+		}
+	}
+
+	if changes := configChanges(oldConfig, oldConfig); len(changes) != 0 {
+		t.Errorf("相同配置不应有变更: %v", changes)
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReloadKeepsListenFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := models.SaveServerConfig(path, &models.ServerConfig{Password: "pw", Listen: ":18080"}); err != nil {
+		t.Fatal(err)
+	}
+	config, err := models.ReadServerConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := *config
+	changed.Listen = ":18081"
+	changed.GRPCListen = "127.0.0.1:19091"
+	changed.HeartbeatListen = ":19092"
+	changed.Thresholds.CPUPercent = 80
+	if err := models.SaveServerConfig(path, &changed); err != nil {
+		t.Fatal(err)
+	}
+
+	// 并发重载应串行执行，结果与单次重载相同
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Reload(path); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := s.cfg()
+	if got.Listen != config.Listen || got.GRPCListen != config.GRPCListen || got.HeartbeatListen != config.HeartbeatListen {
+		t.Errorf("监听地址应保持原值，实际为 %q %q %q", got.Listen, got.GRPCListen, got.HeartbeatListen)
+	}
+	if got.Thresholds.CPUPercent != 80 {
+		t.Errorf("cpu_percent = %v，期望重载为 80", got.Thresholds.CPUPercent)
+	}
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/rules"
+)
+
+// alertRule 解析后的告警规则
+type alertRule struct {
+	models.AlertRule
+	expr *rules.Rule
+}
+
+// compileAlertRules 解析内置规则和 alert_rules
+func compileAlertRules(config *models.ServerConfig) ([]*alertRule, error) {
+	var compiled []*alertRule
+	for _, rule := range config.EffectiveAlertRules() {
+		expr, err := rules.Parse(rule.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("告警规则 %s 解析失败: %v", rule.Name, err)
+		}
+		compiled = append(compiled, &alertRule{AlertRule: rule, expr: expr})
+	}
+	return compiled, nil
+}
+
+// uses 规则是否引用了给定变量之一
+func (r *alertRule) uses(vars []string) bool {
+	for _, name := range r.expr.Vars() {
+		for _, v := range vars {
+			if name == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// alertRules 返回当前生效的告警规则，重载时整体替换
+func (s *Server) alertRules() []*alertRule {
+	s.configMutex.RLock()
+	defer s.configMutex.RUnlock()
+	return s.rules
+}
+
+// nodeEnv 以节点最近一次上报为规则表达式提供变量
+type nodeEnv struct {
+	node       *models.NodeStatus
+	thresholds models.Threshold
+	nodes      map[string]*models.NodeStatus // 全部节点，用于主机名冲突判断
+}
+
+func (e nodeEnv) Number(name string) (float64, bool) {
+	m := &e.node.Metrics
+	switch name {
+	case "cpu":
+		cpu := m.CPUPercent
+		if m.Stats != nil && m.Stats.CPUPercent != nil {
+			cpu = selectAggregate(*m.Stats.CPUPercent, e.thresholds.CPUAggregate, cpu)
+		}
+		return cpu, true
+	case "mem":
+		return m.MemoryPercent(), true
+	case "mem_used":
+		return float64(m.MemoryUsed), true
+	case "mem_total":
+		return float64(m.MemoryTotal), true
+	case "net_in_mbps", "net_out_mbps", "bandwidth_mbps":
+		return e.bandwidthMbps(name)
+	case "bandwidth_threshold_mbps":
+		// 取客户端上报阈值，若无则回退到服务端全局阈值
+		if e.node.LastThresholdMbps > 0 {
+			return e.node.LastThresholdMbps, true
+		}
+		return e.thresholds.BandwidthMbps, true
+	case "uptime":
+		return float64(m.UptimeSeconds), true
+	case "clock_skew":
+		return float64(e.node.ClockSkewSeconds), true
+	case "speed_down_mbps", "speed_up_mbps", "speed_mbps":
+		result := e.node.SpeedTest
+		if result == nil || result.Error != "" {
+			return 0, false
+		}
+		switch {
+		case name == "speed_down_mbps":
+			return result.DownloadMbps, true
+		case name == "speed_up_mbps" || result.UploadMbps < result.DownloadMbps:
+			return result.UploadMbps, true
+		}
+		return result.DownloadMbps, true
+	case "hostname_nodes":
+		return float64(len(hostnameNodes(e.nodes, e.node))), true
+	}
+	return 0, false
+}
+
+// selectAggregate 按配置选取区间统计值，未配置或为current时使用当前值
+func selectAggregate(stats models.AggregateStats, name string, current float64) float64 {
+	if value, ok := stats.Get(name); ok {
+		return value
+	}
+	return current
+}
+
+// bandwidthMbps 按 bandwidth_aggregate 取带宽，有网卡正在重建计数基线时本次速率不完整，视为不可用
+func (e nodeEnv) bandwidthMbps(name string) (float64, bool) {
+	m := &e.node.Metrics
+	for _, iface := range m.Interfaces {
+		if iface.Status == models.InterfaceStatusNew || iface.Status == models.InterfaceStatusReset {
+			return 0, false
+		}
+	}
+
+	inBps, outBps := float64(m.NetworkInBps), float64(m.NetworkOutBps)
+	if stats := m.Stats; stats != nil {
+		inBps = selectAggregate(stats.NetworkInBps, e.thresholds.BandwidthAggregate, inBps)
+		outBps = selectAggregate(stats.NetworkOutBps, e.thresholds.BandwidthAggregate, outBps)
+	}
+	inMbps := inBps / 125000.0 // 1 Mbps = 125000 bytes/s
+	outMbps := outBps / 125000.0
+
+	switch name {
+	case "net_in_mbps":
+		return inMbps, true
+	case "net_out_mbps":
+		return outMbps, true
+	}
+	if outMbps < inMbps {
+		return outMbps, true
+	}
+	return inMbps, true
+}
+
+func (e nodeEnv) Tag(key string) string {
+	return e.node.Tags[key]
+}
+
+// evaluateAlertRules 对节点依次求值告警规则（须持有 s.mutex），指定 vars 时只求值引用了其中变量的规则，
+// 用于测速结果、时钟偏差等不随上报指标一起更新的值。
+// 条件满足并持续 for 指定的时长后告警，不再满足时恢复；变量不可用时保持原状态。
+func (s *Server) evaluateAlertRules(node *models.NodeStatus, vars ...string) {
+	now := time.Now()
+	env := nodeEnv{node: node, thresholds: s.cfg().Thresholds, nodes: s.nodes}
+
+	for _, rule := range s.alertRules() {
+		if len(vars) > 0 && !rule.uses(vars) {
+			continue
+		}
+		result, ok := rule.expr.Eval(env)
+		if !ok {
+			continue
+		}
+
+		key := incidentKey(node.Key(), rule.Name)
+		if !result.Matched {
+			delete(s.rulePending, key)
+			if ruleAlerted(node, rule.Name) {
+				s.resolveRule(node, rule, result)
+			}
+			continue
+		}
+
+		since, pending := s.rulePending[key]
+		if !pending {
+			since = now
+			s.rulePending[key] = since
+		}
+		if !ruleAlerted(node, rule.Name) {
+			if now.Sub(since) < rule.expr.For {
+				continue
+			}
+			s.fireRule(node, rule, result, since)
+		}
+		s.incidents.observeRule(node.Key(), rule.Name, result.Value, rule.expr.Below())
+	}
+}
+
+// fireRule 规则触发告警，告警记录从条件首次满足时开始计时
+func (s *Server) fireRule(node *models.NodeStatus, rule *alertRule, result rules.Result, since time.Time) {
+	setRuleAlerted(node, rule.Name, true)
+
+	event := nodeEvent(models.EventAlertFiring, node, rule.Name, result.Value, result.Threshold)
+	event.Severity = rule.Severity
+	s.events.publish(event)
+	s.incidents.startRule(node.Key(), node, &rule.AlertRule, since, result.Value, result.Threshold)
+
+	if s.shouldNotify(node, rule.Name) {
+		msg := s.ruleMessage(node, rule, models.AlertStateFiring, result)
+		if kind, ok := topProcessesKind(rule.expr.Vars()); ok {
+			msg.Details = topProcessesDetails(node, kind, s.bot().Lang())
+		}
+		if rule.uses([]string{"hostname_nodes"}) {
+			msg.NodeIDs = hostnameNodeIDs(s.nodes, node)
+		}
+		s.sendAlert(msg)
+	}
+	alertLog.Warn("规则告警", "node", node.Hostname, "rule", rule.Name, "severity", rule.Severity,
+		"value", result.Value, "threshold", result.Threshold, "expr", rule.Expr)
+}
+
+// resolveRule 规则条件不再满足，告警恢复
+func (s *Server) resolveRule(node *models.NodeStatus, rule *alertRule, result rules.Result) {
+	setRuleAlerted(node, rule.Name, false)
+
+	event := nodeEvent(models.EventAlertResolved, node, rule.Name, result.Value, result.Threshold)
+	event.Severity = rule.Severity
+	s.events.publish(event)
+	duration := s.incidents.end(node.Key(), rule.Name, result.Value, models.ResolutionRecovered)
+
+	if s.shouldNotify(node, rule.Name) {
+		msg := s.ruleMessage(node, rule, models.AlertStateResolved, result)
+		msg.Duration = duration
+		s.sendAlert(msg)
+	}
+	alertLog.Info("规则告警已恢复", "node", node.Hostname, "rule", rule.Name, "value", result.Value, "threshold", result.Threshold)
+}
+
+func (s *Server) ruleMessage(node *models.NodeStatus, rule *alertRule, state string, result rules.Result) models.AlertMessage {
+	msg := s.alertMessage(node, rule.Name, state, result.Value, result.Threshold)
+	msg.Severity = rule.Severity
+	msg.Labels = rule.Labels
+	msg.Expr = rule.expr.String()
+	return msg
+}
+
+// resetRuleAlerts 结束节点上的规则告警而不发送通知（须持有 s.mutex）：
+// 节点离线时结束全部规则告警，规则被删除时只结束 keep 返回false的规则
+func (s *Server) resetRuleAlerts(node *models.NodeStatus, resolution string, keep func(name string) bool) {
+	for _, name := range ruleAlertNames(node) {
+		if keep != nil && keep(name) {
+			continue
+		}
+		setRuleAlerted(node, name, false)
+		s.incidents.end(node.Key(), name, 0, resolution)
+	}
+
+	prefix := node.Key() + "|"
+	for key := range s.rulePending {
+		if strings.HasPrefix(key, prefix) && (keep == nil || !keep(strings.TrimPrefix(key, prefix))) {
+			delete(s.rulePending, key)
+		}
+	}
+}
+
+// pruneRuleAlerts 重载后结束已删除规则的告警
+func (s *Server) pruneRuleAlerts() {
+	names := make(map[string]bool)
+	for _, rule := range s.alertRules() {
+		names[rule.Name] = true
+	}
+	keep := func(name string) bool { return names[name] }
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, node := range s.nodes {
+		removed := false
+		for _, name := range ruleAlertNames(node) {
+			if !names[name] {
+				alertLog.Info("告警规则已删除，结束进行中的告警", "node", node.Hostname, "rule", name)
+				removed = true
+			}
+		}
+		s.resetRuleAlerts(node, models.ResolutionRuleRemoved, keep)
+		if removed {
+			s.publishStatus(node)
+		}
+	}
+}
+
+// ruleAlertNames 节点正在告警的规则
+func ruleAlertNames(node *models.NodeStatus) []string {
+	var names []string
+	for _, name := range []string{models.AlertBandwidth, models.AlertCPU, models.AlertMemory,
+		models.AlertSpeedTest, models.AlertClockSkew, models.AlertHostnameConflict} {
+		if ruleAlerted(node, name) {
+			names = append(names, name)
+		}
+	}
+	return append(names, node.RuleAlerts...)
+}
+
+// ruleAlerted 节点是否正在按该规则告警；内置规则沿用原有的状态字段
+func ruleAlerted(node *models.NodeStatus, name string) bool {
+	switch name {
+	case models.AlertBandwidth:
+		return node.BandwidthAlerted
+	case models.AlertCPU:
+		return node.CPUAlerted
+	case models.AlertMemory:
+		return node.MemoryAlerted
+	case models.AlertSpeedTest:
+		return node.SpeedTestAlerted
+	case models.AlertClockSkew:
+		return node.ClockSkewAlerted
+	case models.AlertHostnameConflict:
+		return node.HostnameConflict
+	}
+	for _, alert := range node.RuleAlerts {
+		if alert == name {
+			return true
+		}
+	}
+	return false
+}
+
+// setRuleAlerted 设置规则告警状态；RuleAlerts 每次替换为新切片，不影响已发布的节点快照
+func setRuleAlerted(node *models.NodeStatus, name string, alerted bool) {
+	switch name {
+	case models.AlertBandwidth:
+		node.BandwidthAlerted = alerted
+		return
+	case models.AlertCPU:
+		node.CPUAlerted = alerted
+		return
+	case models.AlertMemory:
+		node.MemoryAlerted = alerted
+		return
+	case models.AlertSpeedTest:
+		node.SpeedTestAlerted = alerted
+		return
+	case models.AlertClockSkew:
+		node.ClockSkewAlerted = alerted
+		return
+	case models.AlertHostnameConflict:
+		node.HostnameConflict = alerted
+		return
+	}
+
+	var alerts []string
+	for _, alert := range node.RuleAlerts {
+		if alert != name {
+			alerts = append(alerts, alert)
+		}
+	}
+	if alerted {
+		alerts = append(alerts, name)
+	}
+	node.RuleAlerts = alerts
+}
+
+// topProcessesKind 按规则引用的第一个资源类变量选择告警附带的进程排行
+func topProcessesKind(vars []string) (int, bool) {
+	for _, name := range vars {
+		switch {
+		case name == "cpu":
+			return topByCPU, true
+		case strings.HasPrefix(name, "mem"):
+			return topByMemory, true
+		case strings.HasPrefix(name, "net_") || name == "bandwidth_mbps":
+			return topByNetwork, true
+		}
+	}
+	return 0, false
+}
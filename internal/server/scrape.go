@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// scrapeLoop 按间隔抓取配置的客户端，结果进入与主动上报相同的状态更新流程。
+// 每轮重新读取抓取目标和间隔，使配置重载后立即生效
+func (s *Server) scrapeLoop() {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	for {
+		config := s.cfg()
+		next := time.After(time.Duration(config.ScrapeIntervalSeconds) * time.Second)
+
+		var wg sync.WaitGroup
+		for _, target := range config.ScrapeTargets {
+			wg.Add(1)
+			go func(target models.ScrapeTarget) {
+				defer wg.Done()
+				s.scrapeTarget(httpClient, target)
+			}(target)
+		}
+		wg.Wait()
+
+		<-next
+	}
+}
+
+// scrapeTarget 抓取单个客户端；失败时累加节点的连续失败次数，用于离线判断
+func (s *Server) scrapeTarget(httpClient *http.Client, target models.ScrapeTarget) {
+	req, err := s.fetchScrape(httpClient, target)
+	if err != nil {
+		s.recordScrapeFailure(target, err)
+		return
+	}
+
+	if target.Hostname != "" {
+		req.Hostname = target.Hostname
+	}
+	if req.Hostname == "" {
+		s.recordScrapeFailure(target, fmt.Errorf("缺少主机名"))
+		return
+	}
+
+	s.mutex.Lock()
+	s.scrapeHosts[target.URL] = req.NodeKey()
+	s.mutex.Unlock()
+
+	// 客户端尚未产生新的采集时跳过，避免重复计入样本
+	if s.isDuplicateReport(req) {
+		s.mutex.Lock()
+		if node, ok := s.nodes[req.NodeKey()]; ok {
+			node.ScrapeFailures = 0
+		}
+		s.mutex.Unlock()
+		return
+	}
+
+	resp := s.updateNodeStatus(req)
+	if err := s.sendScrapeAck(httpClient, target, req, resp); err != nil {
+		ingestLog.Warn("回传抓取响应失败", "node", req.Hostname, "error", err)
+	}
+}
+
+// sendScrapeAck 将上报响应回传给客户端，使仅抓取的节点也能收到下发配置和测速请求
+func (s *Server) sendScrapeAck(httpClient *http.Client, target models.ScrapeTarget, req *models.ReportRequest, resp *models.ReportResponse) error {
+	// 抓取结果在客户端缓存了不定的时长，服务端时间不能用于校时
+	resp.ServerTime = 0
+
+	ack := models.ScrapeAck{Session: req.Session, Seq: req.Seq, Response: resp}
+	if req.SpeedTest != nil {
+		ack.SpeedTestTimestamp = req.SpeedTest.Timestamp
+	}
+	body, err := json.Marshal(ack)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, scrapeURL(target), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Password", s.scrapePassword(target))
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	var response models.APIResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("响应解析失败: %v", err)
+	}
+	if !response.Success {
+		return fmt.Errorf("客户端返回错误: %s", response.Message)
+	}
+	return nil
+}
+
+func scrapeURL(target models.ScrapeTarget) string {
+	return strings.TrimRight(target.URL, "/") + "/api/metrics"
+}
+
+func (s *Server) scrapePassword(target models.ScrapeTarget) string {
+	if target.Password != "" {
+		return target.Password
+	}
+	return s.cfg().Password
+}
+
+func (s *Server) fetchScrape(httpClient *http.Client, target models.ScrapeTarget) (*models.ReportRequest, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, scrapeURL(target), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("X-Password", s.scrapePassword(target))
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var req models.ReportRequest
+	response := models.APIResponse{Data: &req}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("响应解析失败: %v", err)
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("客户端返回错误: %s", response.Message)
+	}
+
+	return &req, nil
+}
+
+func (s *Server) recordScrapeFailure(target models.ScrapeTarget, err error) {
+	s.mutex.Lock()
+	node, ok := s.nodes[s.scrapeHosts[target.URL]]
+	if !ok {
+		s.mutex.Unlock()
+		ingestLog.Warn("抓取失败", "url", target.URL, "error", err)
+		return
+	}
+
+	node.ScrapeFailures++
+	failures := node.ScrapeFailures
+	hostname := node.Hostname
+	s.mutex.Unlock()
+
+	ingestLog.Warn("抓取节点失败", "node", hostname, "failures", failures, "error", err)
+
+	// 达到上限时立即判定离线，不必等待下一轮离线检查
+	if failures == s.cfg().ScrapeFailureLimit {
+		s.checkOfflineNodes()
+	}
+}
@@ -2,119 +2,303 @@ package server
 
 import (
 	"encoding/json"
-	"log"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"bandwidth-monitor/internal/compress"
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/logging"
 	"bandwidth-monitor/internal/models"
 	"bandwidth-monitor/internal/telegram"
+
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	config *models.ServerConfig
-	tgBot  *telegram.Bot
-	nodes  map[string]*models.NodeStatus
-	mutex  sync.RWMutex
-	server *http.Server
+	config     *models.ServerConfig
+	tgBot      *telegram.Bot
+	nodes      map[string]*models.NodeStatus
+	mutex      sync.RWMutex
+	server     *http.Server
+	grpcServer *grpc.Server
+	batchMutex sync.Mutex
+
+	// 节点状态变化订阅者
+	subscribers map[chan models.NodeStatus]struct{}
+	subMutex    sync.Mutex
+
+	scrapeHosts  map[string]string // 抓取地址 -> 节点（NodeKey）
+	events       *eventBus
+	incidents    *incidentStore
+	silences     []*models.Silence
+	silenceMutex sync.Mutex
+
+	heartbeatConn net.PacketConn
+	heartbeatSeq  map[string]int64 // 节点 -> 最近一次心跳时间戳
+
+	rules       []*alertRule         // 由配置生成的告警规则
+	rulePending map[string]time.Time // 节点键+规则 -> 条件首次满足的时间，for 未满足前不告警
+
+	// 保护 config、tgBot 和 rules，热重载时整体替换
+	configMutex sync.RWMutex
+	// 串行化重载，文件监视与 SIGHUP 同时触发时避免基于过期配置比较和替换
+	reloadMutex sync.Mutex
 }
 
-func NewServer(config *models.ServerConfig, tgBot *telegram.Bot) *Server {
-	return &Server{
-		config: config,
-		tgBot:  tgBot,
-		nodes:  make(map[string]*models.NodeStatus),
+// 各模块的日志记录器
+var (
+	ingestLog = logging.Component("ingest")   // 上报、抓取、心跳、测速结果
+	alertLog  = logging.Component("alerts")   // 告警状态变化、静默、告警记录
+	notifyLog = logging.Component("notifier") // Telegram通知、事件流
+	adminLog  = logging.Component("admin")    // 节点管理、配置重载
+)
+
+// errInvalidPassword 上报密码错误
+var errInvalidPassword = i18n.NewError("api.invalid_password")
+
+// NewServer 创建服务端；告警规则无法解析时返回错误，不能在没有指标告警的情况下运行
+func NewServer(config *models.ServerConfig, tgBot *telegram.Bot) (*Server, error) {
+	bot, err := configureBot(tgBot, config)
+	if err != nil {
+		// 启动前已校验配置，这里仅作兜底
+		adminLog.Error("加载通知模板失败，使用内置文本", "error", err)
+		bot = tgBot.WithLocale(notifyLocale(config))
+	}
+	alertRules, err := compileAlertRules(config)
+	if err != nil {
+		return nil, err
 	}
+
+	return &Server{
+		config:       config,
+		tgBot:        bot,
+		rules:        alertRules,
+		rulePending:  make(map[string]time.Time),
+		nodes:        make(map[string]*models.NodeStatus),
+		subscribers:  make(map[chan models.NodeStatus]struct{}),
+		scrapeHosts:  make(map[string]string),
+		heartbeatSeq: make(map[string]int64),
+		events:       newEventBus(),
+	}, nil
+}
+
+// cfg 返回当前生效的配置，重载时整体替换，调用方不应修改
+func (s *Server) cfg() *models.ServerConfig {
+	s.configMutex.RLock()
+	defer s.configMutex.RUnlock()
+	return s.config
+}
+
+// bot 返回当前的Telegram机器人，未配置时为nil
+func (s *Server) bot() *telegram.Bot {
+	s.configMutex.RLock()
+	defer s.configMutex.RUnlock()
+	return s.tgBot
 }
 
 func (s *Server) Start() error {
-	mux := http.NewServeMux()
+	incidents, err := loadIncidentStore(s.cfg().IncidentFile, s.cfg().IncidentRetentionDays)
+	if err != nil {
+		return err
+	}
+	s.incidents = incidents
 
-	// API路由
-	mux.HandleFunc("/api/report", s.handleReport)
-	mux.HandleFunc("/api/status", s.handleStatus)
-	mux.HandleFunc("/api/test-telegram", s.handleTestTelegram)
+	silences, err := loadSilences(s.cfg().SilenceFile)
+	if err != nil {
+		return err
+	}
+	s.silences = silences
+
+	// 启动UDP心跳服务（可选），需在离线监控之前启动
+	if s.cfg().HeartbeatListen != "" {
+		if err := s.startHeartbeat(); err != nil {
+			return err
+		}
+	}
 
 	// 启动监控goroutine
 	go s.monitorNodes()
 
+	// 启动抓取goroutine，未配置抓取目标时空转，便于重载时加入
+	go s.scrapeLoop()
+
+	// 启动gRPC服务（可选）
+	if s.cfg().GRPCListen != "" {
+		if err := s.startGRPC(); err != nil {
+			return err
+		}
+	}
+
 	s.server = &http.Server{
-		Addr:    s.config.Listen,
-		Handler: mux,
+		Addr:    s.cfg().Listen,
+		Handler: s.Handler(),
 	}
 
 	return s.server.ListenAndServe()
 }
 
+// Handler 返回HTTP接口的路由
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// API路由
+	mux.HandleFunc("/api/report", s.handleReport)
+	mux.HandleFunc("/api/report/batch", s.handleReportBatch)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/summary", s.handleSummary)
+	mux.HandleFunc("/api/test-telegram", s.handleTestTelegram)
+	mux.HandleFunc("/api/speedtest/download", s.handleSpeedTestDownload)
+	mux.HandleFunc("/api/speedtest/upload", s.handleSpeedTestUpload)
+	mux.HandleFunc("/api/speedtest/request", s.handleSpeedTestRequest)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/incidents", s.handleIncidents)
+	mux.HandleFunc("/api/silences", s.handleSilences)
+	mux.HandleFunc("/api/nodes", s.handleNodes)
+
+	return mux
+}
+
 func (s *Server) Stop() {
+	if s.heartbeatConn != nil {
+		s.heartbeatConn.Close()
+	}
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
 	if s.server != nil {
 		s.server.Close()
 	}
+	if s.incidents != nil {
+		s.incidents.flush()
+	}
 }
 
 func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		s.sendResponse(w, false, "仅支持POST方法", nil)
+		s.sendResponse(w, false, s.tr(r, "api.post_only"), nil)
 		return
 	}
 
 	var req models.ReportRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.sendResponse(w, false, "JSON解析失败", nil)
+	if err := compress.DecodeJSONBody(r, &req); err != nil {
+		s.sendResponse(w, false, s.tr(r, "api.invalid_json"), nil)
 		return
 	}
 
-	// 验证密码
-	if req.Password != s.config.Password {
-		s.sendResponse(w, false, "密码错误", nil)
+	resp, err := s.ingestReport(&req)
+	if err != nil {
+		s.sendResponse(w, false, s.trErr(r, err), nil)
 		return
 	}
 
-	// 更新节点状态（包含客户端上报的阈值）
-	s.updateNodeStatus(req.Hostname, req.Metrics, req.EffectiveThresholdMbps)
+	s.sendResponse(w, true, s.tr(r, "api.report_ok"), resp)
+}
 
-	s.sendResponse(w, true, "上报成功", nil)
+// ingestReport 校验并处理一次上报（HTTP与gRPC共用）
+func (s *Server) ingestReport(req *models.ReportRequest) (*models.ReportResponse, error) {
+	// 验证密码（服务端密码或 reporter 密钥）
+	if !s.authorized(req.Password, models.RoleReporter) {
+		return nil, errInvalidPassword
+	}
+
+	// 更新节点状态（包含客户端上报的阈值）
+	resp := s.updateNodeStatus(req)
+	s.recordClockSkew(req, resp)
+	return resp, nil
 }
 
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	if r.Method != http.MethodGet {
+		s.sendResponse(w, false, s.tr(r, "api.get_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleViewer) {
+		return
+	}
+
+	// 不带查询参数（token 除外）时保持原有的以主机名为键的返回格式，key=node_id 时以节点标识为键
+	values := r.URL.Query()
+	values.Del("token")
+	values.Del("lang")
+	key := values.Get("key")
+	values.Del("key")
+	if key != "" && key != "hostname" && key != "node_id" {
+		s.sendResponse(w, false, s.tr(r, "api.invalid_param", "key", key), nil)
+		return
+	}
+	if len(values) == 0 {
+		s.mutex.RLock()
+		defer s.mutex.RUnlock()
+
+		if key == "node_id" {
+			s.sendResponse(w, true, s.tr(r, "api.status_ok"), s.nodes)
+			return
+		}
+		s.sendResponse(w, true, s.tr(r, "api.status_ok"), nodesByHostname(s.nodes))
+		return
+	}
 
-	s.sendResponse(w, true, "获取状态成功", s.nodes)
+	query, err := parseStatusQuery(values)
+	if err != nil {
+		s.sendResponse(w, false, s.trErr(r, err), nil)
+		return
+	}
+	s.sendResponse(w, true, s.tr(r, "api.status_ok"), query.apply(s.snapshotNodes()))
 }
 
 func (s *Server) handleTestTelegram(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		s.sendResponse(w, false, "仅支持POST方法", nil)
+		s.sendResponse(w, false, s.tr(r, "api.post_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleAdmin) {
 		return
 	}
 
-	if s.tgBot == nil {
-		s.sendResponse(w, false, "Telegram机器人未配置", nil)
+	if s.bot() == nil {
+		s.sendResponse(w, false, s.tr(r, "api.telegram_not_configured"), nil)
 		return
 	}
 
-	if err := s.tgBot.SendTestMessage(); err != nil {
-		s.sendResponse(w, false, "发送测试消息失败: "+err.Error(), nil)
+	if err := s.bot().SendTestMessage(); err != nil {
+		s.sendResponse(w, false, s.tr(r, "api.test_message_failed", err), nil)
 		return
 	}
 
-	s.sendResponse(w, true, "测试消息发送成功", nil)
+	s.sendResponse(w, true, s.tr(r, "api.test_message_ok"), nil)
 }
 
-func (s *Server) updateNodeStatus(hostname string, metrics models.SystemMetrics, thresholdMbps float64) {
+func (s *Server) updateNodeStatus(req *models.ReportRequest) *models.ReportResponse {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	hostname := req.Hostname
+	metrics := req.Metrics
+	thresholdMbps := req.EffectiveThresholdMbps
+
 	now := time.Now()
 	wasOffline := false
 	isNew := false
 
-	// 检查节点是否存在
-	node, exists := s.nodes[hostname]
+	// 检查节点是否存在（按节点标识，旧版客户端按主机名）
+	key := req.NodeKey()
+	node, exists := s.nodes[key]
+	if !exists && req.NodeID != "" {
+		// 客户端升级后首次携带节点标识，沿用按主机名保存的状态
+		if legacy, ok := s.nodes[hostname]; ok && legacy.NodeID == "" {
+			delete(s.nodes, hostname)
+			legacy.NodeID = req.NodeID
+			s.nodes[key] = legacy
+			node, exists = legacy, true
+			ingestLog.Info("节点开始使用节点标识", "node", hostname, "node_id", req.NodeID)
+		}
+	}
 	if !exists {
 		isNew = true
 		node = &models.NodeStatus{
+			NodeID:            req.NodeID,
 			Hostname:          hostname,
 			IsOnline:          true,
 			BandwidthAlerted:  false,
@@ -123,10 +307,20 @@ func (s *Server) updateNodeStatus(hostname string, metrics models.SystemMetrics,
 			ReportSamples:     0,
 			LastThresholdMbps: thresholdMbps,
 		}
-		s.nodes[hostname] = node
-		log.Printf("新节点上线: %s", hostname)
+		s.nodes[key] = node
+		ingestLog.Info("新节点上线", "node", hostname, "node_id", req.NodeID)
 	} else {
 		wasOffline = !node.IsOnline
+		if node.Hostname != hostname {
+			ingestLog.Info("节点主机名变更", "node_id", key, "old", node.Hostname, "new", hostname)
+			node.Hostname = hostname
+		}
+	}
+
+	if exists && s.heartbeatLost(node, now) {
+		// 心跳中断但完整上报仍在到达，改回按上报超时判断离线，避免反复上下线
+		ingestLog.Info("UDP心跳中断，改按上报超时判断离线", "node", hostname)
+		node.LastHeartbeat = time.Time{}
 	}
 
 	// 更新节点信息
@@ -134,77 +328,81 @@ func (s *Server) updateNodeStatus(hostname string, metrics models.SystemMetrics,
 	node.Metrics = metrics
 	node.IsOnline = true
 	node.ReportSamples++
+	node.ScrapeFailures = 0
+	node.Tags = req.Tags
+	node.Via = req.Via
+	node.Relay = req.Relay
+	if req.Timestamp > node.LastReportTimestamp {
+		node.LastReportTimestamp = req.Timestamp
+	}
+	if req.Seq > 0 && (req.Session != node.LastReportSession || req.Seq > node.LastReportSeq) {
+		node.LastReportSession = req.Session
+		node.LastReportSeq = req.Seq
+	}
 	if thresholdMbps > 0 {
 		node.LastThresholdMbps = thresholdMbps
 	}
 
 	// 如果节点首次出现或重新上线，发送通知
-	if s.tgBot != nil {
-		if isNew || wasOffline {
-			if err := s.tgBot.SendOnlineAlert(hostname); err != nil {
-				log.Printf("发送上线告警失败: %v", err)
-			}
+	if isNew {
+		s.emitNodeEvent(models.EventNodeNew, node, "", 0, 0)
+	} else if wasOffline {
+		s.emitNodeEvent(models.EventNodeOnline, node, "", 0, 0)
+	}
+	if isNew || wasOffline {
+		duration := s.incidents.end(key, models.AlertOffline, 0, models.ResolutionRecovered)
+		if s.shouldNotify(node, models.AlertOffline) {
+			msg := s.alertMessage(node, models.AlertOffline, models.AlertStateResolved, 0, float64(s.cfg().Thresholds.OfflineSeconds))
+			msg.Duration = duration
+			s.sendAlert(msg)
 		}
 	}
 
-	// 检查带宽告警（跳过首个样本防止冷启动误报）
+	// 按告警规则检查指标（跳过首个样本防止冷启动误报）
 	if node.ReportSamples >= 2 {
-		s.checkBandwidthAlert(node)
-		s.checkCPUAlert(node)
-		s.checkMemoryAlert(node)
+		s.evaluateAlertRules(node)
 	}
-}
 
-func (s *Server) checkBandwidthAlert(node *models.NodeStatus) {
-	// 计算当前带宽 (Mbps)
-	inMbps := float64(node.Metrics.NetworkInBps) / 125000.0  // 1 Mbps = 125000 bytes/s
-	outMbps := float64(node.Metrics.NetworkOutBps) / 125000.0
-	
-	// 取上下行的最小值进行异常检测（瓶颈检测）
-	currentMbps := inMbps
-	if outMbps < inMbps {
-		currentMbps = outMbps
-	}
-
-	// 取客户端上报阈值，若无则回退到服务端全局阈值
-	threshold := node.LastThresholdMbps
-	if threshold <= 0 {
-		threshold = s.config.Thresholds.BandwidthMbps
-	}
-
-	// 检查是否需要告警
-	if currentMbps < threshold {
-		if !node.BandwidthAlerted {
-			// 第一次触发告警
-			node.BandwidthAlerted = true
-			if s.tgBot != nil {
-				if err := s.tgBot.SendBandwidthAlert(
-					node.Hostname,
-					currentMbps,
-					threshold,
-				); err != nil {
-					log.Printf("发送带宽告警失败: %v", err)
-				}
-			}
-			log.Printf("节点 %s 带宽告警: %.2f Mbps < %.2f Mbps",
-				node.Hostname, currentMbps, threshold)
-		}
-	} else {
-		// 带宽恢复正常
-		if node.BandwidthAlerted {
-			node.BandwidthAlerted = false
-			if s.tgBot != nil {
-				if err := s.tgBot.SendBandwidthRecover(node.Hostname, currentMbps, threshold); err != nil {
-					log.Printf("发送恢复通知失败: %v", err)
-				}
-			}
-			log.Printf("节点 %s 带宽恢复正常: %.2f Mbps", node.Hostname, currentMbps)
+	// 保存随本次上报附带的测速结果
+	if req.SpeedTest != nil {
+		s.recordSpeedTest(node, req.SpeedTest)
+	}
+
+	// 下发待执行的测速请求
+	resp := &models.ReportResponse{
+		// 告警期间请求客户端持续附带进程快照
+		TopProcessesRequested: len(ruleAlertNames(node)) > 0,
+	}
+	if node.SpeedTestPending {
+		node.SpeedTestPending = false
+		resp.SpeedTestRequested = true
+	}
+
+	// 下发与客户端已应用版本不同的集中配置
+	if req.ConfigVersion != node.ConfigVersion && req.ConfigVersion != "" {
+		ingestLog.Info("节点已应用配置版本", "node", hostname, "version", req.ConfigVersion)
+	}
+	node.ConfigVersion = req.ConfigVersion
+	node.DesiredConfigVersion = ""
+	if desired := s.desiredClientConfig(hostname); desired != nil {
+		node.DesiredConfigVersion = desired.Version
+		if desired.Version != req.ConfigVersion {
+			resp.Config = desired
 		}
 	}
+
+	s.publishStatus(node)
+
+	return resp
 }
 
 func (s *Server) monitorNodes() {
-	ticker := time.NewTicker(30 * time.Second)
+	// 启用心跳时缩短检查周期，使心跳超时能及时生效
+	interval := 30 * time.Second
+	if s.heartbeatConn != nil {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for range ticker.C {
@@ -217,114 +415,36 @@ func (s *Server) checkOfflineNodes() {
 	defer s.mutex.Unlock()
 
 	now := time.Now()
-	offlineThreshold := time.Duration(s.config.Thresholds.OfflineSeconds) * time.Second
+	offlineThreshold := time.Duration(s.cfg().Thresholds.OfflineSeconds) * time.Second
 
-	for hostname, node := range s.nodes {
-		if node.IsOnline && now.Sub(node.LastSeen) > offlineThreshold {
+	for _, node := range s.nodes {
+		hostname := node.Hostname
+		// 上报超时、心跳超时，或抓取模式下连续失败达到上限
+		scrapeFailed := s.cfg().ScrapeFailureLimit > 0 && node.ScrapeFailures >= s.cfg().ScrapeFailureLimit
+		if node.IsOnline && (now.Sub(node.LastSeen) > offlineThreshold || s.heartbeatLost(node, now) || scrapeFailed) {
 			// 节点离线
 			node.IsOnline = false
-			node.BandwidthAlerted = false // 重置带宽告警状态
-			node.CPUAlerted = false       // 重置CPU告警状态
-			node.MemoryAlerted = false    // 重置内存告警状态
-
-			if s.tgBot != nil {
-				if err := s.tgBot.SendOfflineAlert(hostname, now.Sub(node.LastSeen)); err != nil {
-					log.Printf("发送离线告警失败: %v", err)
-				}
-			}
-
-			log.Printf("节点 %s 离线，最后上报时间: %s", hostname, node.LastSeen.Format("2006-01-02 15:04:05"))
-		}
-	}
-}
 
-// checkCPUAlert 检查CPU告警
-func (s *Server) checkCPUAlert(node *models.NodeStatus) {
-	cpuThreshold := s.config.Thresholds.CPUPercent
-	if cpuThreshold <= 0 {
-		return // 未配置CPU阈值
-	}
-
-	currentCPU := node.Metrics.CPUPercent
-
-	if currentCPU > cpuThreshold {
-		if !node.CPUAlerted {
-			// 第一次触发CPU告警
-			node.CPUAlerted = true
-			if s.tgBot != nil {
-				if err := s.tgBot.SendCPUAlert(
-					node.Hostname,
-					currentCPU,
-					cpuThreshold,
-				); err != nil {
-					log.Printf("发送CPU告警失败: %v", err)
-				}
-			}
-			log.Printf("节点 %s CPU告警: %.2f%% > %.2f%%",
-				node.Hostname, currentCPU, cpuThreshold)
-		}
-	} else {
-		if node.CPUAlerted {
-			// CPU使用率恢复正常
-			node.CPUAlerted = false
-			if s.tgBot != nil {
-				if err := s.tgBot.SendCPURecover(
-					node.Hostname,
-					currentCPU,
-					cpuThreshold,
-				); err != nil {
-					log.Printf("发送CPU恢复通知失败: %v", err)
-				}
+			s.emitNodeEvent(models.EventNodeOffline, node, models.AlertOffline, now.Sub(node.LastSeen).Seconds(), offlineThreshold.Seconds())
+			// 规则告警（含主机名冲突）随离线重置，告警记录一并结束；离线记录从最后一次上报开始计时
+			s.resetRuleAlerts(node, models.ResolutionOffline, nil)
+			s.incidents.start(node.Key(), node, models.AlertOffline, node.LastSeen, now.Sub(node.LastSeen).Seconds(), offlineThreshold.Seconds())
+			if s.shouldNotify(node, models.AlertOffline) {
+				msg := s.alertMessage(node, models.AlertOffline, models.AlertStateFiring, now.Sub(node.LastSeen).Seconds(), offlineThreshold.Seconds())
+				msg.Duration = now.Sub(node.LastSeen)
+				s.sendAlert(msg)
 			}
-			log.Printf("节点 %s CPU已恢复: %.2f%% <= %.2f%%",
-				node.Hostname, currentCPU, cpuThreshold)
-		}
-	}
-}
 
-// checkMemoryAlert 检查内存告警
-func (s *Server) checkMemoryAlert(node *models.NodeStatus) {
-	memoryThreshold := s.config.Thresholds.MemoryPercent
-	if memoryThreshold <= 0 {
-		return // 未配置内存阈值
-	}
-
-	// 计算内存使用百分比
-	currentMemory := float64(node.Metrics.MemoryUsed) / float64(node.Metrics.MemoryTotal) * 100
-
-	if currentMemory > memoryThreshold {
-		if !node.MemoryAlerted {
-			// 第一次触发内存告警
-			node.MemoryAlerted = true
-			if s.tgBot != nil {
-				if err := s.tgBot.SendMemoryAlert(
-					node.Hostname,
-					currentMemory,
-					memoryThreshold,
-				); err != nil {
-					log.Printf("发送内存告警失败: %v", err)
-				}
-			}
-			log.Printf("节点 %s 内存告警: %.2f%% > %.2f%%",
-				node.Hostname, currentMemory, memoryThreshold)
-		}
-	} else {
-		if node.MemoryAlerted {
-			// 内存使用率恢复正常
-			node.MemoryAlerted = false
-			if s.tgBot != nil {
-				if err := s.tgBot.SendMemoryRecover(
-					node.Hostname,
-					currentMemory,
-					memoryThreshold,
-				); err != nil {
-					log.Printf("发送内存恢复通知失败: %v", err)
-				}
+			if node.LastHeartbeat.IsZero() {
+				alertLog.Warn("节点离线", "node", hostname, "last_seen", node.LastSeen)
+			} else {
+				alertLog.Warn("节点离线", "node", hostname, "last_seen", node.LastSeen, "last_heartbeat", node.LastHeartbeat)
 			}
-			log.Printf("节点 %s 内存已恢复: %.2f%% <= %.2f%%",
-				node.Hostname, currentMemory, memoryThreshold)
+			s.publishStatus(node)
 		}
 	}
+
+	s.pruneNodes(now)
 }
 
 func (s *Server) sendResponse(w http.ResponseWriter, success bool, message string, data interface{}) {
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"bandwidth-monitor/internal/compress"
+	"bandwidth-monitor/internal/models"
+)
+
+// loadSilences 读取静默规则文件中未过期的规则，文件不存在时为空
+func loadSilences(path string) ([]*models.Silence, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取静默规则失败: %v", err)
+	}
+
+	var silences []*models.Silence
+	if err := json.Unmarshal(data, &silences); err != nil {
+		return nil, fmt.Errorf("解析静默规则失败: %v", err)
+	}
+
+	now := time.Now()
+	active := silences[:0]
+	for _, silence := range silences {
+		if silence.EndsAt.After(now) {
+			active = append(active, silence)
+		}
+	}
+	return active, nil
+}
+
+// saveSilences 把当前静默规则写入文件（须持有 silenceMutex），只在管理接口修改时调用，不在上报路径上
+func (s *Server) saveSilences() {
+	path := s.cfg().SilenceFile
+	data, err := json.MarshalIndent(s.silences, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		alertLog.Error("保存静默规则失败", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		alertLog.Error("保存静默规则失败", "error", err)
+	}
+}
+
+// handleSilences 管理告警静默规则：GET 列出、POST 创建、DELETE ?id= 删除
+func (s *Server) handleSilences(w http.ResponseWriter, r *http.Request) {
+	// 查看静默规则需要 viewer，修改需要 admin
+	required := models.RoleAdmin
+	if r.Method == http.MethodGet {
+		required = models.RoleViewer
+	}
+	if !s.authorize(w, r, required) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.sendResponse(w, true, s.tr(r, "api.silences_ok"), s.activeSilences())
+	case http.MethodPost:
+		var req models.SilenceRequest
+		if err := compress.DecodeJSONBody(r, &req); err != nil {
+			s.sendResponse(w, false, s.tr(r, "api.invalid_json"), nil)
+			return
+		}
+		if req.DurationMinutes <= 0 {
+			s.sendResponse(w, false, s.tr(r, "api.invalid_silence_duration"), nil)
+			return
+		}
+		if _, err := path.Match(req.Hostname, ""); err != nil {
+			s.sendResponse(w, false, s.tr(r, "api.invalid_hostname_glob", req.Hostname), nil)
+			return
+		}
+		s.sendResponse(w, true, s.tr(r, "api.silence_created"), s.createSilence(req))
+	case http.MethodDelete:
+		if !s.deleteSilence(r.URL.Query().Get("id")) {
+			s.sendResponse(w, false, s.tr(r, "api.silence_not_found"), nil)
+			return
+		}
+		s.sendResponse(w, true, s.tr(r, "api.silence_deleted"), nil)
+	default:
+		s.sendResponse(w, false, s.tr(r, "api.method_not_allowed"), nil)
+	}
+}
+
+func (s *Server) createSilence(req models.SilenceRequest) *models.Silence {
+	idBytes := make([]byte, 6)
+	rand.Read(idBytes)
+
+	now := time.Now()
+	silence := &models.Silence{
+		ID:        hex.EncodeToString(idBytes),
+		Hostname:  req.Hostname,
+		Alert:     req.Alert,
+		Comment:   req.Comment,
+		CreatedAt: now,
+		EndsAt:    now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+	}
+
+	s.silenceMutex.Lock()
+	s.silences = append(s.silences, silence)
+	s.saveSilences()
+	s.silenceMutex.Unlock()
+
+	alertLog.Info("已创建静默规则", "id", silence.ID, "node", silence.Hostname, "alert", silence.Alert, "ends_at", silence.EndsAt)
+	s.events.publish(models.Event{Type: models.EventSilenceCreated, Hostname: silence.Hostname, Silence: silence})
+
+	return silence
+}
+
+func (s *Server) deleteSilence(id string) bool {
+	s.silenceMutex.Lock()
+	defer s.silenceMutex.Unlock()
+
+	for i, silence := range s.silences {
+		if silence.ID == id {
+			s.silences = append(s.silences[:i], s.silences[i+1:]...)
+			s.saveSilences()
+			return true
+		}
+	}
+	return false
+}
+
+// activeSilences 返回未过期的静默规则并清理已过期的（文件中的过期规则在下次保存或加载时清理）
+func (s *Server) activeSilences() []*models.Silence {
+	s.silenceMutex.Lock()
+	defer s.silenceMutex.Unlock()
+
+	now := time.Now()
+	active := s.silences[:0]
+	for _, silence := range s.silences {
+		if silence.EndsAt.After(now) {
+			active = append(active, silence)
+		}
+	}
+	s.silences = active
+
+	return append([]*models.Silence{}, active...)
+}
+
+// isSilenced 判断节点的某类告警是否被静默
+func (s *Server) isSilenced(hostname, alert string) bool {
+	for _, silence := range s.activeSilences() {
+		if silence.Alert != "" && silence.Alert != alert {
+			continue
+		}
+		if silence.Hostname == "" {
+			return true
+		}
+		if ok, _ := path.Match(silence.Hostname, hostname); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldNotify 判断是否发送Telegram通知（未配置机器人、节点已停用或退役、命中静默规则时不发送）
+func (s *Server) shouldNotify(node *models.NodeStatus, alert string) bool {
+	if s.bot() == nil {
+		return false
+	}
+	if node.State != models.NodeStateActive {
+		return false
+	}
+	if s.isSilenced(node.Hostname, alert) {
+		alertLog.Debug("通知已静默", "node", node.Hostname, "alert", alert)
+		return false
+	}
+	return true
+}
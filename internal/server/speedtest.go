@@ -0,0 +1,130 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bandwidth-monitor/internal/models"
+)
+
+// 单次测速允许的最长时长，防止测速端点被滥用
+const maxSpeedTestSeconds = 30
+
+// 上传测速允许的最高速率，请求体超过 测速时长×该速率 时视为滥用
+const maxSpeedTestUploadMbps = 100000
+
+// 上传测速在测速时长之外额外允许的读取时间，覆盖连接建立与网络延迟
+const speedTestUploadGrace = 10 * time.Second
+
+// speedTestChunk 下载测速时重复写出的数据块
+var speedTestChunk = make([]byte, 64*1024)
+
+// handleSpeedTestDownload 下载测速数据源：在指定时长内持续写出数据
+func (s *Server) handleSpeedTestDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.sendResponse(w, false, s.tr(r, "api.get_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleReporter) {
+		return
+	}
+
+	seconds, err := strconv.Atoi(r.URL.Query().Get("seconds"))
+	if err != nil || seconds <= 0 || seconds > maxSpeedTestSeconds {
+		s.sendResponse(w, false, s.tr(r, "api.invalid_speedtest_seconds"), nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := w.Write(speedTestChunk); err != nil {
+			return
+		}
+	}
+}
+
+// handleSpeedTestUpload 上传测速数据汇：在 seconds 指定的时长内读取并丢弃请求体，返回接收字节数
+func (s *Server) handleSpeedTestUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendResponse(w, false, s.tr(r, "api.post_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleReporter) {
+		return
+	}
+
+	// 未带 seconds 的旧客户端按最长时长处理
+	seconds := maxSpeedTestSeconds
+	if v := r.URL.Query().Get("seconds"); v != "" {
+		var err error
+		if seconds, err = strconv.Atoi(v); err != nil || seconds <= 0 || seconds > maxSpeedTestSeconds {
+			s.sendResponse(w, false, s.tr(r, "api.invalid_speedtest_seconds"), nil)
+			return
+		}
+	}
+
+	// 请求体大小和读取时间都以测速时长为上限，防止长时间占用连接
+	start := time.Now()
+	duration := time.Duration(seconds) * time.Second
+	http.NewResponseController(w).SetReadDeadline(start.Add(duration + speedTestUploadGrace))
+	body := http.MaxBytesReader(w, r.Body, int64(seconds)*maxSpeedTestUploadMbps*125000)
+	received, err := io.Copy(io.Discard, body)
+	if err != nil {
+		s.sendResponse(w, false, s.tr(r, "api.speedtest_read_failed", err), nil)
+		return
+	}
+
+	s.sendResponse(w, true, s.tr(r, "api.speedtest_upload_ok"), map[string]interface{}{
+		"bytes":       received,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+}
+
+// handleSpeedTestRequest 请求节点在下次上报后执行测速（hostname为空表示全部节点）
+func (s *Server) handleSpeedTestRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendResponse(w, false, s.tr(r, "api.post_only"), nil)
+		return
+	}
+	if !s.authorize(w, r, models.RoleAdmin) {
+		return
+	}
+
+	hostname := r.URL.Query().Get("hostname")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var requested []string
+	for key, node := range s.nodes {
+		if hostname == "" || node.Hostname == hostname || key == hostname {
+			node.SpeedTestPending = true
+			requested = append(requested, node.Hostname)
+		}
+	}
+
+	if len(requested) == 0 {
+		s.sendResponse(w, false, s.tr(r, "api.node_not_found"), nil)
+		return
+	}
+
+	adminLog.Info("已请求节点测速", "nodes", requested)
+	s.sendResponse(w, true, s.tr(r, "api.speedtest_requested"), requested)
+}
+
+// recordSpeedTest 保存测速结果并求值引用测速结果的告警规则
+func (s *Server) recordSpeedTest(node *models.NodeStatus, result *models.SpeedTestResult) {
+	node.SpeedTest = result
+
+	if result.Error != "" {
+		ingestLog.Warn("节点测速失败", "node", node.Hostname, "error", result.Error)
+		return
+	}
+
+	ingestLog.Info("节点测速结果", "node", node.Hostname, "download_mbps", result.DownloadMbps, "upload_mbps", result.UploadMbps)
+
+	s.evaluateAlertRules(node, "speed_mbps", "speed_down_mbps", "speed_up_mbps")
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSpeedTestUpload(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	body := bytes.Repeat([]byte{'x'}, 1<<20)
+	tests := []struct {
+		query   string
+		success bool
+	}{
+		{"?seconds=1", true},
+		{"", true},
+		{"?seconds=30", true},
+		{"?seconds=31", false},
+		{"?seconds=0", false},
+		{"?seconds=abc", false},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/speedtest/upload"+tt.query, bytes.NewReader(body))
+		req.Header.Set("X-Password", "pw")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+
+		var response struct {
+			Success bool   `json:"success"`
+			Message string `json:"message"`
+			Data    struct {
+				Bytes int64 `json:"bytes"`
+			} `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if response.Success != tt.success {
+			t.Errorf("%s: success = %v（%s），期望 %v", tt.query, response.Success, response.Message, tt.success)
+			continue
+		}
+		if tt.success && response.Data.Bytes != int64(len(body)) {
+			t.Errorf("%s: 接收 %d 字节，期望 %d", tt.query, response.Data.Bytes, len(body))
+		}
+		if !tt.success && response.Message != "测速时长无效" {
+			t.Errorf("%s: message = %q", tt.query, response.Message)
+		}
+	}
+}
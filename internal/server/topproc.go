@@ -0,0 +1,53 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+	"bandwidth-monitor/internal/telegram"
+)
+
+// 进程排行类别
+const (
+	topByCPU = iota
+	topByMemory
+	topByNetwork
+)
+
+// topProcessesDetails 将节点最近一次上报的进程快照格式化为告警附加信息
+func topProcessesDetails(node *models.NodeStatus, kind int, lang i18n.Lang) string {
+	top := node.Metrics.TopProcesses
+	if top == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	switch kind {
+	case topByCPU:
+		for _, p := range top.ByCPU {
+			fmt.Fprintf(&b, "%6d %-16s %6.1f%%\n", p.PID, p.Name, p.CPUPercent)
+		}
+		return telegram.FormatDetails(lang.T("tg.top_cpu"), b.String())
+	case topByMemory:
+		for _, p := range top.ByMemory {
+			fmt.Fprintf(&b, "%6d %-16s %8.1f MB\n", p.PID, p.Name, float64(p.RSSBytes)/1024/1024)
+		}
+		return telegram.FormatDetails(lang.T("tg.top_memory"), b.String())
+	case topByNetwork:
+		for _, p := range top.ByNetwork {
+			fmt.Fprintf(&b, "%6d %-16s ↓%.2fMbps ↑%.2fMbps\n", p.PID, p.Name,
+				float64(p.NetInBps)/125000.0, float64(p.NetOutBps)/125000.0)
+		}
+		// 宿主网络命名空间内无法按进程区分流量，附带CPU排行作为参考
+		if b.Len() == 0 {
+			for _, p := range top.ByCPU {
+				fmt.Fprintf(&b, "%6d %-16s %6.1f%%\n", p.PID, p.Name, p.CPUPercent)
+			}
+			return telegram.FormatDetails(lang.T("tg.top_cpu"), b.String())
+		}
+		return telegram.FormatDetails(lang.T("tg.top_network"), b.String())
+	}
+	return ""
+}
@@ -2,14 +2,26 @@ package telegram
 
 import (
 	"fmt"
+	"strings"
+	"text/template"
 	"time"
 
+	"bandwidth-monitor/internal/i18n"
+	"bandwidth-monitor/internal/models"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type Bot struct {
 	api    *tgbotapi.BotAPI
 	chatID int64
+
+	// 通知文本的语言及时间显示时区
+	lang     i18n.Lang
+	location *time.Location
+
+	// 用户定义的告警通知模板，模板名见 models.AlertTemplateName
+	templates map[string]*template.Template
 }
 
 func NewBot(token string, chatID int64) (*Bot, error) {
@@ -19,125 +31,180 @@ func NewBot(token string, chatID int64) (*Bot, error) {
 	}
 
 	return &Bot{
-		api:    api,
-		chatID: chatID,
+		api:      api,
+		chatID:   chatID,
+		lang:     i18n.Default,
+		location: time.Local,
 	}, nil
 }
 
-func (b *Bot) SendMessage(text string) error {
-	msg := tgbotapi.NewMessage(b.chatID, text)
-	msg.ParseMode = tgbotapi.ModeMarkdown
+// WithLocale 返回使用指定语言和时区的副本，与原机器人共用连接；location 为nil时使用本地时区
+func (b *Bot) WithLocale(lang i18n.Lang, location *time.Location) *Bot {
+	if b == nil {
+		return nil
+	}
+	if location == nil {
+		location = time.Local
+	}
+	bot := *b
+	bot.lang = lang
+	bot.location = location
+	return &bot
+}
 
-	_, err := b.api.Send(msg)
-	return err
+// WithTemplates 返回使用指定告警通知模板的副本，与原机器人共用连接
+func (b *Bot) WithTemplates(templates map[string]*template.Template) *Bot {
+	if b == nil {
+		return nil
+	}
+	bot := *b
+	bot.templates = templates
+	return &bot
 }
 
-func (b *Bot) SendBandwidthAlert(hostname string, currentMbps, thresholdMbps float64) error {
-	text := fmt.Sprintf("🚨 *带宽告警*\n\n"+
-		"节点: `%s`\n"+
-		"当前带宽: `%.2f Mbps`\n"+
-		"告警阈值: `%.2f Mbps`\n"+
-		"时间: `%s`",
-		hostname,
-		currentMbps,
-		thresholdMbps,
-		time.Now().Format("2006-01-02 15:04:05"))
+// Lang 返回通知使用的语言，未配置机器人时为默认语言
+func (b *Bot) Lang() i18n.Lang {
+	if b == nil {
+		return i18n.Default
+	}
+	return b.lang
+}
 
-	return b.SendMessage(text)
+// now 返回按通知时区格式化的当前时间
+func (b *Bot) now() string {
+	location := time.Local
+	if b != nil {
+		location = b.location
+	}
+	return time.Now().In(location).Format("2006-01-02 15:04:05")
 }
 
-func (b *Bot) SendBandwidthRecover(hostname string, currentMbps, thresholdMbps float64) error {
-	text := fmt.Sprintf("🟢 *带宽已恢复*\n\n"+
-		"节点: `%s`\n"+
-		"当前带宽: `%.2f Mbps`\n"+
-		"告警阈值: `%.2f Mbps`\n"+
-		"时间: `%s`",
-		hostname,
-		currentMbps,
-		thresholdMbps,
-		time.Now().Format("2006-01-02 15:04:05"))
-	return b.SendMessage(text)
+// FormatDetails 将附加信息格式化为代码块，追加到告警消息末尾
+func FormatDetails(title, body string) string {
+	if body == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n*%s*\n```\n%s```", title, body)
 }
 
-func (b *Bot) SendOfflineAlert(hostname string, offlineDuration time.Duration) error {
-	text := fmt.Sprintf("❌ *节点离线告警*\n\n"+
-		"节点: `%s`\n"+
-		"离线时长: `%.0f分钟`\n"+
-		"时间: `%s`",
-		hostname,
-		offlineDuration.Minutes(),
-		time.Now().Format("2006-01-02 15:04:05"))
+func (b *Bot) SendMessage(text string) error {
+	// 重载配置移除机器人时，已取得旧引用的调用直接忽略
+	if b == nil {
+		return nil
+	}
+	msg := tgbotapi.NewMessage(b.chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
 
-	return b.SendMessage(text)
+	_, err := b.api.Send(msg)
+	return err
 }
 
-func (b *Bot) SendOnlineAlert(hostname string) error {
-	text := fmt.Sprintf("✅ *节点重新上线*\n\n"+
-		"节点: `%s`\n"+
-		"时间: `%s`",
-		hostname,
-		time.Now().Format("2006-01-02 15:04:05"))
+// SendAlert 发送告警或恢复通知：配置了对应模板时按模板渲染，否则使用内置文本。
+// 模板执行失败时改发内置文本，并返回模板错误。
+func (b *Bot) SendAlert(msg models.AlertMessage) error {
+	if b == nil {
+		return nil
+	}
+	msg.Time = msg.Time.In(b.location)
+	if msg.Duration > 0 {
+		msg.DurationText = b.lang.Duration(msg.Duration)
+	}
 
-	return b.SendMessage(text)
-}
+	tmpl, ok := b.templates[models.AlertTemplateName(msg.Alert, msg.State)]
+	if !ok {
+		return b.SendMessage(b.alertText(msg))
+	}
 
-func (b *Bot) SendTestMessage() error {
-	text := "🤖 *带宽监控系统*\n\n测试消息发送成功！"
-	return b.SendMessage(text)
+	var text strings.Builder
+	if err := tmpl.Execute(&text, msg); err != nil {
+		if sendErr := b.SendMessage(b.alertText(msg)); sendErr != nil {
+			return sendErr
+		}
+		return fmt.Errorf("通知模板 %s 执行失败，已发送内置文本: %v", tmpl.Name(), err)
+	}
+	return b.SendMessage(text.String())
 }
 
-// CPU告警相关方法
-func (b *Bot) SendCPUAlert(hostname string, currentPercent, thresholdPercent float64) error {
-	text := fmt.Sprintf("🔥 *CPU告警*\n\n"+
-		"节点: `%s`\n"+
-		"当前CPU: `%.2f%%`\n"+
-		"告警阈值: `%.2f%%`\n"+
-		"时间: `%s`",
-		hostname,
-		currentPercent,
-		thresholdPercent,
-		time.Now().Format("2006-01-02 15:04:05"))
+// alertText 内置的告警通知文本：告警附带进程排行等附加信息，恢复附带持续时长
+func (b *Bot) alertText(msg models.AlertMessage) string {
+	now := msg.Time.Format("2006-01-02 15:04:05")
+	firing := msg.State == models.AlertStateFiring
+
+	var key string
+	var args []interface{}
+	switch msg.Alert {
+	case models.AlertBandwidth:
+		key = pick(firing, "tg.bandwidth_alert", "tg.bandwidth_recover")
+		args = []interface{}{msg.Hostname, msg.Value, msg.Threshold, now}
+	case models.AlertCPU:
+		key = pick(firing, "tg.cpu_alert", "tg.cpu_recover")
+		args = []interface{}{msg.Hostname, msg.Value, msg.Threshold, now}
+	case models.AlertMemory:
+		key = pick(firing, "tg.memory_alert", "tg.memory_recover")
+		args = []interface{}{msg.Hostname, msg.Value, msg.Threshold, now}
+	case models.AlertSpeedTest:
+		key = pick(firing, "tg.speedtest_alert", "tg.speedtest_recover")
+		args = []interface{}{msg.Hostname, msg.Value, msg.Threshold, now}
+	case models.AlertClockSkew:
+		key = pick(firing, "tg.clock_skew_alert", "tg.clock_skew_recover")
+		args = []interface{}{msg.Hostname, int64(msg.Value), int(msg.Threshold), now}
+	case models.AlertHostnameConflict:
+		if firing {
+			key, args = "tg.hostname_conflict_alert", []interface{}{msg.Hostname, strings.Join(msg.NodeIDs, ", "), now}
+		} else {
+			key, args = "tg.hostname_conflict_recover", []interface{}{msg.Hostname, now}
+		}
+	case models.AlertOffline:
+		if firing {
+			// 离线告警的持续时长即离线时长，已包含在正文中
+			return b.lang.T("tg.offline_alert", msg.Hostname, msg.Duration.Minutes(), now)
+		}
+		key, args = "tg.online_alert", []interface{}{msg.Hostname, now}
+	default:
+		switch {
+		case msg.Expr == "":
+			key, args = pick(firing, "tg.generic_alert", "tg.generic_recover"), []interface{}{msg.Alert, msg.Hostname, msg.Value, msg.Threshold, now}
+		case firing:
+			severity := b.lang.T("tg.severity_" + msg.Severity)
+			key, args = "tg.rule_alert", []interface{}{severityIcons[msg.Severity], msg.Alert, severity, msg.Hostname, msg.Expr, msg.Value, msg.Threshold, now}
+		default:
+			key, args = "tg.rule_recover", []interface{}{msg.Alert, msg.Hostname, msg.Expr, msg.Value, now}
+		}
+	}
 
-	return b.SendMessage(text)
+	if firing {
+		return b.lang.T(key, args...) + msg.Details
+	}
+	return b.lang.T(key, args...) + b.durationLine(msg.Duration)
 }
 
-func (b *Bot) SendCPURecover(hostname string, currentPercent, thresholdPercent float64) error {
-	text := fmt.Sprintf("✅ *CPU已恢复*\n\n"+
-		"节点: `%s`\n"+
-		"当前CPU: `%.2f%%`\n"+
-		"告警阈值: `%.2f%%`\n"+
-		"时间: `%s`",
-		hostname,
-		currentPercent,
-		thresholdPercent,
-		time.Now().Format("2006-01-02 15:04:05"))
-	return b.SendMessage(text)
+// severityIcons 规则告警按级别使用的图标
+var severityIcons = map[string]string{
+	models.SeverityInfo:     "ℹ️",
+	models.SeverityWarning:  "⚠️",
+	models.SeverityCritical: "🚨",
 }
 
-// 内存告警相关方法
-func (b *Bot) SendMemoryAlert(hostname string, currentPercent, thresholdPercent float64) error {
-	text := fmt.Sprintf("💾 *内存告警*\n\n"+
-		"节点: `%s`\n"+
-		"当前内存: `%.2f%%`\n"+
-		"告警阈值: `%.2f%%`\n"+
-		"时间: `%s`",
-		hostname,
-		currentPercent,
-		thresholdPercent,
-		time.Now().Format("2006-01-02 15:04:05"))
+func pick(firing bool, firingKey, resolvedKey string) string {
+	if firing {
+		return firingKey
+	}
+	return resolvedKey
+}
 
-	return b.SendMessage(text)
+func (b *Bot) SendTestMessage() error {
+	return b.SendMessage(b.Lang().T("tg.test_message"))
 }
 
-func (b *Bot) SendMemoryRecover(hostname string, currentPercent, thresholdPercent float64) error {
-	text := fmt.Sprintf("🟢 *内存已恢复*\n\n"+
-		"节点: `%s`\n"+
-		"当前内存: `%.2f%%`\n"+
-		"告警阈值: `%.2f%%`\n"+
-		"时间: `%s`",
-		hostname,
-		currentPercent,
-		thresholdPercent,
-		time.Now().Format("2006-01-02 15:04:05"))
+func (b *Bot) SendNodePrunedNotice(hostname string, offlineDuration time.Duration) error {
+	text := b.Lang().T("tg.node_pruned", hostname, offlineDuration.Hours()/24, b.now())
 	return b.SendMessage(text)
 }
+
+// durationLine 恢复通知中的告警持续时长，未知时为空
+func (b *Bot) durationLine(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return b.Lang().T("tg.duration", b.Lang().Duration(d))
+}